@@ -0,0 +1,61 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/flowdeskmarkets/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+resource "clickhouse_db" "dictionaries_db" {
+  name = "dictionaries"
+}
+
+resource "clickhouse_table" "countries" {
+  database = clickhouse_db.dictionaries_db.name
+  name     = "countries"
+  engine   = "MergeTree"
+  order_by = ["id"]
+  column {
+    name = "id"
+    type = "UInt64"
+  }
+  column {
+    name = "name"
+    type = "String"
+  }
+}
+
+resource "clickhouse_dictionary" "countries" {
+  database    = clickhouse_db.dictionaries_db.name
+  name        = "countries_dict"
+  primary_key = ["id"]
+  attribute {
+    name = "id"
+    type = "UInt64"
+  }
+  attribute {
+    name    = "name"
+    type    = "String"
+    default = "'unknown'"
+  }
+  source {
+    type = "CLICKHOUSE"
+    parameters = {
+      db    = clickhouse_db.dictionaries_db.name
+      table = clickhouse_table.countries.name
+    }
+  }
+  layout {
+    type = "hashed"
+  }
+  lifetime_min = 300
+  lifetime_max = 600
+
+  // change it to reload the dictionary data
+  reload_trigger = "1"
+}
@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/flowdeskmarkets/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+resource "clickhouse_function" "linear_equation" {
+  name       = "linear_equation"
+  arguments  = ["x", "k", "b"]
+  expression = "k * x + b"
+}
@@ -25,6 +25,15 @@ func Quote(elems []string) []string {
 	return quotedElems
 }
 
+func Contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 func StringSetToList(set *schema.Set) []string {
 	var list []string
 	for _, item := range set.List() {
@@ -52,6 +61,153 @@ func NormalizeQuery(query string) string {
 	return query
 }
 
+// NormalizeExpression removes the whitespaces of an expression that don't separate two words, so
+// expressions written with a different spacing can be compared. Quoted literals are kept as is.
+func NormalizeExpression(expression string) string {
+	var b strings.Builder
+	var quote byte
+	pendingSpace := false
+	isWordChar := func(ch byte) bool {
+		return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+	}
+	for i := 0; i < len(expression); i++ {
+		ch := expression[i]
+		if quote != 0 {
+			b.WriteByte(ch)
+			if ch == '\\' && i+1 < len(expression) {
+				i++
+				b.WriteByte(expression[i])
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace && b.Len() > 0 && isWordChar(b.String()[b.Len()-1]) && isWordChar(ch) {
+			b.WriteByte(' ')
+		}
+		pendingSpace = false
+		if ch == '\'' || ch == '`' || ch == '"' {
+			quote = ch
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// SplitTopLevel splits a comma separated list of expressions, ignoring the commas
+// found inside parentheses, brackets or quoted literals
+func SplitTopLevel(s string) []string {
+	var parts []string
+	var quote byte
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(parts) > 0 {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// FindTopLevelKeyword returns the position of the first occurrence of a keyword in a query,
+// starting from the given position and ignoring the ones inside parentheses or quoted literals.
+// The keyword is matched case insensitively, as a whole word. It returns -1 if not found.
+func FindTopLevelKeyword(query string, keyword string, from int) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+			continue
+		case '(', '[':
+			depth++
+			continue
+		case ')', ']':
+			depth--
+			continue
+		}
+		if i < from || depth != 0 || !strings.EqualFold(query[i:min(i+len(keyword), len(query))], keyword) {
+			continue
+		}
+		startsWord := i == 0 || !isWordByte(query[i-1])
+		endsWord := i+len(keyword) == len(query) || !isWordByte(query[i+len(keyword)])
+		if startsWord && endsWord {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindClosingParenthesis returns the position of the parenthesis closing the one opened at
+// the given position, ignoring the ones inside quoted literals. It returns -1 if not found.
+func FindClosingParenthesis(s string, open int) int {
+	var quote byte
+	depth := 0
+	for i := open; i < len(s); i++ {
+		ch := s[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || ch == '.' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
 func GetCreateStatement(resourceType string) string {
 	resourceType = strings.ToUpper(resourceType)
 	isDatabase := resourceType == "DATABASE"
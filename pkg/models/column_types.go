@@ -0,0 +1,146 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+)
+
+// Clickhouse reports the canonical name of a type in system.columns, not the alias used to
+// create the column
+var columnTypeAliases = map[string]string{
+	"BOOLEAN":  "Bool",
+	"TINYINT":  "Int8",
+	"SMALLINT": "Int16",
+	"INT":      "Int32",
+	"INTEGER":  "Int32",
+	"BIGINT":   "Int64",
+	"FLOAT":    "Float32",
+	"REAL":     "Float32",
+	"DOUBLE":   "Float64",
+	"TEXT":     "String",
+	"VARCHAR":  "String",
+	"BLOB":     "String",
+}
+
+type NestedField struct {
+	Name string
+	Type string
+}
+
+// NormalizeColumnType returns a canonical representation of a column type so two
+// definitions can be compared structurally, regardless of whitespaces, aliases or
+// the order of the Variant members (Clickhouse sorts them)
+func NormalizeColumnType(columnType string) string {
+	columnType = collapseTypeWhitespaces(columnType)
+
+	open := strings.IndexByte(columnType, '(')
+	if open <= 0 || !strings.HasSuffix(columnType, ")") || strings.ContainsAny(columnType[:open], "'`\"") {
+		if alias, ok := columnTypeAliases[strings.ToUpper(columnType)]; ok {
+			return alias
+		}
+		return columnType
+	}
+
+	name := columnType[:open]
+	args := common.SplitTopLevel(columnType[open+1 : len(columnType)-1])
+	for i, arg := range args {
+		// named elements of Tuple/Nested/JSON are written as "name Type"
+		if fieldName, fieldType, ok := strings.Cut(arg, " "); ok && isIdentifier(fieldName) {
+			args[i] = fieldName + " " + NormalizeColumnType(fieldType)
+		} else {
+			args[i] = NormalizeColumnType(arg)
+		}
+	}
+	if name == "Variant" {
+		sort.Strings(args)
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(args, ","))
+}
+
+// ColumnTypesEqual compares two column types structurally
+func ColumnTypesEqual(a string, b string) bool {
+	return NormalizeColumnType(a) == NormalizeColumnType(b)
+}
+
+// IsExpandedObjectType tells whether the type read from Clickhouse is the Tuple a JSON or Object
+// column of the state was expanded to, its sub-columns being inferred from the inserted data
+func IsExpandedObjectType(stateType string, serverType string) bool {
+	stateType = NormalizeColumnType(stateType)
+	isObject := stateType == "JSON" || strings.HasPrefix(stateType, "JSON(") || strings.HasPrefix(stateType, "Object(")
+	return isObject && strings.HasPrefix(NormalizeColumnType(serverType), "Tuple(")
+}
+
+// GetNestedFields returns the fields of a Nested column type
+func GetNestedFields(columnType string) ([]NestedField, bool) {
+	columnType = collapseTypeWhitespaces(columnType)
+	if !strings.HasPrefix(columnType, "Nested(") || !strings.HasSuffix(columnType, ")") {
+		return nil, false
+	}
+
+	var fields []NestedField
+	for _, arg := range common.SplitTopLevel(columnType[len("Nested(") : len(columnType)-1]) {
+		fieldName, fieldType, ok := strings.Cut(arg, " ")
+		if !ok {
+			return nil, false
+		}
+		fields = append(fields, NestedField{Name: strings.Trim(fieldName, "`"), Type: fieldType})
+	}
+	return fields, true
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	if strings.HasPrefix(s, "`") && strings.HasSuffix(s, "`") {
+		return true
+	}
+	for i, r := range s {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !(i > 0 && (isDigit || r == '.')) {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseTypeWhitespaces removes the whitespaces around parentheses, commas and equal
+// signs, and replaces any other run of whitespaces with a single space
+func collapseTypeWhitespaces(columnType string) string {
+	var b strings.Builder
+	var quote byte
+	pendingSpace := false
+	for i := 0; i < len(columnType); i++ {
+		ch := columnType[i]
+		if quote != 0 {
+			b.WriteByte(ch)
+			if ch == '\\' && i+1 < len(columnType) {
+				i++
+				b.WriteByte(columnType[i])
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace && b.Len() > 0 {
+			last := b.String()[b.Len()-1]
+			if !strings.ContainsRune("(,=", rune(last)) && !strings.ContainsRune("),=", rune(ch)) {
+				b.WriteByte(' ')
+			}
+		}
+		pendingSpace = false
+		if ch == '\'' || ch == '`' || ch == '"' {
+			quote = ch
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
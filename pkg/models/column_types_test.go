@@ -0,0 +1,77 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestColumnTypesEqual(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"Map(String, UInt64)", "Map(String,UInt64)", true},
+		{"Tuple(a String,  b Array(Int8))", "Tuple(a String, b Array(Int8))", true},
+		{"Nested(a String, b UInt8)", "Nested(\n\ta String,\n\tb UInt8\n)", true},
+		{"Variant(UInt64, String)", "Variant(String, UInt64)", true},
+		{"Nullable(INT)", "Nullable(Int32)", true},
+		{"JSON(max_dynamic_paths = 10)", "JSON(max_dynamic_paths=10)", true},
+		{"JSON", "JSON(max_dynamic_paths=10)", false},
+		{"Tuple(a Int8)", "JSON", false},
+		{"Object('json')", "Tuple(a Int8, b String)", false},
+		{"DateTime64(3, 'UTC')", "DateTime64(3,'UTC')", true},
+		{"Enum8('a b' = 1)", "Enum8('a  b' = 1)", false},
+		{"Map(String, UInt64)", "Map(String, UInt32)", false},
+		{"Tuple(a String)", "Tuple(b String)", false},
+		{"String", "Tuple(a String)", false},
+	}
+
+	for _, tt := range testCases {
+		if result := models.ColumnTypesEqual(tt.a, tt.b); result != tt.expected {
+			t.Errorf("ColumnTypesEqual(%q, %q) = %v, expected %v", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestIsExpandedObjectType(t *testing.T) {
+	testCases := []struct {
+		stateType, serverType string
+		expected              bool
+	}{
+		{"Object('json')", "Tuple(a Int8, b String)", true},
+		{"JSON", "Tuple(a Int8)", true},
+		{"Tuple(a Int8)", "JSON", false},
+		{"Tuple(a Int8)", "Tuple(a Int16)", false},
+		{"JSON", "JSON(max_dynamic_paths=10)", false},
+	}
+
+	for _, tt := range testCases {
+		if result := models.IsExpandedObjectType(tt.stateType, tt.serverType); result != tt.expected {
+			t.Errorf("IsExpandedObjectType(%q, %q) = %v, expected %v", tt.stateType, tt.serverType, result, tt.expected)
+		}
+	}
+}
+
+func TestCollapseNestedColumns(t *testing.T) {
+	table := models.TableResource{
+		Columns: []models.ColumnDefinition{
+			{Name: "key", Type: "UInt64"},
+			{Name: "n.a", Type: "Array(String)"},
+			{Name: "n.b", Type: "Array(Array(UInt8))"},
+			{Name: "article.id", Type: "Int32"},
+		},
+	}
+
+	table.CollapseNestedColumns([]string{"n"})
+
+	if len(table.Columns) != 3 {
+		t.Fatalf("expected 3 columns, got %d", len(table.Columns))
+	}
+	if table.Columns[1].Name != "n" || table.Columns[1].Type != "Nested(a String, b Array(UInt8))" {
+		t.Errorf("unexpected nested column %+v", table.Columns[1])
+	}
+	if table.Columns[2].Name != "article.id" {
+		t.Errorf("expected column article.id to be kept, got %+v", table.Columns[2])
+	}
+}
@@ -3,3 +3,13 @@ package models
 type CHDBResources struct {
 	CHTables []CHTable
 }
+
+// DatabaseResource defines a database and the engine storing or bridging its tables
+type DatabaseResource struct {
+	Cluster      string
+	Name         string
+	Engine       string
+	EngineParams []string
+	Settings     map[string]string
+	Comment      string
+}
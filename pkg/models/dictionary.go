@@ -0,0 +1,112 @@
+package models
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+type DictionaryResource struct {
+	Database    string
+	Name        string
+	Cluster     string
+	Attributes  []DictionaryAttribute
+	PrimaryKey  []string
+	Source      DictionarySource
+	Layout      DictionaryLayout
+	LifetimeMin int
+	LifetimeMax int
+	Range       *DictionaryRange
+	Comment     string
+}
+
+type DictionaryAttribute struct {
+	Name         string
+	Type         string
+	Default      string
+	Expression   string
+	Hierarchical bool
+	Injective    bool
+}
+
+// DictionarySource defines where the dictionary data is loaded from, e.g. a CLICKHOUSE table
+// or an HTTP endpoint, with the parameters of the source
+type DictionarySource struct {
+	Type       string
+	Parameters map[string]string
+}
+
+// DictionaryLayout defines how the dictionary is stored in memory
+type DictionaryLayout struct {
+	Type       string
+	Parameters map[string]string
+}
+
+// DictionaryRange defines the attributes holding the validity range of range_hashed dictionaries
+type DictionaryRange struct {
+	Min string
+	Max string
+}
+
+type CHDictionary struct {
+	Database       string   `ch:"database"`
+	Name           string   `ch:"name"`
+	Status         string   `ch:"status"`
+	KeyNames       []string `ch:"key_names"`
+	KeyTypes       []string `ch:"key_types"`
+	AttributeNames []string `ch:"attribute_names"`
+	AttributeTypes []string `ch:"attribute_types"`
+	LifetimeMin    uint64   `ch:"lifetime_min"`
+	LifetimeMax    uint64   `ch:"lifetime_max"`
+	LastException  string   `ch:"last_exception"`
+	Comment        string   `ch:"comment"`
+}
+
+// ToResource maps the structure of a dictionary, the source and the layout are not part of
+// system.dictionaries until the dictionary is loaded
+func (t *CHDictionary) ToResource() *DictionaryResource {
+	dictionary := DictionaryResource{
+		Database:    t.Database,
+		Name:        t.Name,
+		PrimaryKey:  t.KeyNames,
+		LifetimeMin: int(t.LifetimeMin),
+		LifetimeMax: int(t.LifetimeMax),
+		Comment:     t.Comment,
+	}
+
+	// the key columns are declared as attributes in the dictionary definition
+	for i, name := range t.KeyNames {
+		dictionary.Attributes = append(dictionary.Attributes, DictionaryAttribute{Name: name, Type: t.KeyTypes[i]})
+	}
+	for i, name := range t.AttributeNames {
+		dictionary.Attributes = append(dictionary.Attributes, DictionaryAttribute{Name: name, Type: t.AttributeTypes[i]})
+	}
+	return &dictionary
+}
+
+func (t *DictionaryResource) Validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	isRangeLayout := t.Layout.Type == "range_hashed" || t.Layout.Type == "complex_key_range_hashed"
+	if isRangeLayout && t.Range == nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "missing value",
+			Detail:   "range is required for the range_hashed and complex_key_range_hashed layouts",
+		})
+	}
+	if !isRangeLayout && t.Range != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "range can only be defined for the range_hashed and complex_key_range_hashed layouts",
+		})
+	}
+	if t.LifetimeMin > t.LifetimeMax {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "lifetime_min can't be greater than lifetime_max",
+		})
+	}
+
+	return diags
+}
@@ -0,0 +1,20 @@
+package models
+
+// CHDistributedDDLHost is the status of an ON CLUSTER query on a host of the cluster
+type CHDistributedDDLHost struct {
+	Host          string `ch:"host"`
+	Port          uint16 `ch:"port"`
+	Status        string `ch:"status"`
+	ExceptionCode uint16 `ch:"exception_code"`
+	ExceptionText string `ch:"exception_text"`
+}
+
+// Failed returns whether the query failed on the host
+func (h *CHDistributedDDLHost) Failed() bool {
+	return h.ExceptionCode != 0
+}
+
+// Finished returns whether the host has executed the query, successfully or not
+func (h *CHDistributedDDLHost) Finished() bool {
+	return h.Status == "Finished"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+)
+
+// FunctionResource defines a SQL user defined function, a lambda like `(x, y) -> x + y`
+type FunctionResource struct {
+	Name       string
+	Cluster    string
+	Arguments  []string
+	Expression string
+}
+
+type CHFunction struct {
+	Name        string `ch:"name"`
+	CreateQuery string `ch:"create_query"`
+}
+
+// ToResource extracts the lambda from the create query, `CREATE FUNCTION name AS (x) -> expression`
+func (t *CHFunction) ToResource() *FunctionResource {
+	function := FunctionResource{Name: t.Name}
+
+	start := common.FindTopLevelKeyword(t.CreateQuery, "AS", 0)
+	if start < 0 {
+		return &function
+	}
+	lambda := strings.TrimSpace(t.CreateQuery[start+len("AS"):])
+	if !strings.HasPrefix(lambda, "(") {
+		// a single argument can be written without parentheses
+		argument, expression, _ := strings.Cut(lambda, "->")
+		function.Arguments = []string{strings.TrimSpace(argument)}
+		function.Expression = strings.TrimSpace(expression)
+		return &function
+	}
+	closing := common.FindClosingParenthesis(lambda, 0)
+	if closing < 0 {
+		return &function
+	}
+	function.Arguments = common.SplitTopLevel(lambda[1:closing])
+	function.Expression = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lambda[closing+1:]), "->"))
+	return &function
+}
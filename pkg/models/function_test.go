@@ -0,0 +1,26 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestFunctionToResource(t *testing.T) {
+	testCases := []struct {
+		createQuery string
+		arguments   []string
+		expression  string
+	}{
+		{"CREATE FUNCTION linear_equation AS (x, k, b) -> ((k * x) + b)", []string{"x", "k", "b"}, "((k * x) + b)"},
+		{"CREATE FUNCTION parity AS number -> if((number % 2) = 0, 'even', 'odd')", []string{"number"}, "if((number % 2) = 0, 'even', 'odd')"},
+	}
+
+	for _, tt := range testCases {
+		function := (&models.CHFunction{CreateQuery: tt.createQuery}).ToResource()
+		if !reflect.DeepEqual(function.Arguments, tt.arguments) || function.Expression != tt.expression {
+			t.Errorf("ToResource(%q) = %v, %q, expected %v, %q", tt.createQuery, function.Arguments, function.Expression, tt.arguments, tt.expression)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// graveyardClusterMarker prefixes the cluster segment of the graveyard table names, a hex encoded
+// cluster may only hold digits and would be mistaken for the deletion time otherwise
+const graveyardClusterMarker = "c_"
+
+// GraveyardTableName returns the name of a table moved into the graveyard database. The deletion
+// time and the cluster are kept in the name so it can be purged on the hosts it was renamed on.
+func GraveyardTableName(database string, table string, deletedAt int64, cluster string) string {
+	name := fmt.Sprintf("%s__%s__%d", database, table, deletedAt)
+	if cluster != "" {
+		// the cluster may be a quoted macro, it's hex encoded to be a valid identifier
+		name += "__" + graveyardClusterMarker + hex.EncodeToString([]byte(cluster))
+	}
+	return name
+}
+
+// ParseGraveyardTableName returns the deletion time and the cluster kept in the name of a graveyard
+// table, the tables moved without cluster have no cluster segment
+func ParseGraveyardTableName(name string) (int64, string, bool) {
+	parts := strings.Split(name, "__")
+	if len(parts) < 2 {
+		return 0, "", false
+	}
+
+	var cluster string
+	last := parts[len(parts)-1]
+	if strings.HasPrefix(last, graveyardClusterMarker) {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(last, graveyardClusterMarker))
+		if err != nil || len(parts) < 3 {
+			return 0, "", false
+		}
+		cluster = string(decoded)
+		parts = parts[:len(parts)-1]
+	}
+
+	deletedAt, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return deletedAt, cluster, true
+}
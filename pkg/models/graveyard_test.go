@@ -0,0 +1,41 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestGraveyardTableName(t *testing.T) {
+	tests := []struct {
+		name      string
+		cluster   string
+		tableName string
+	}{
+		{"no cluster", "", "db__events__1700000000"},
+		// "data" is hex encoded to digits only, it must not be read as the deletion time
+		{"digit only hex cluster", "data", "db__events__1700000000__c_64617461"},
+		{"quoted macro cluster", "'{cluster}'", "db__events__1700000000__c_277b636c75737465727d27"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if name := models.GraveyardTableName("db", "events", 1700000000, tt.cluster); name != tt.tableName {
+				t.Errorf("GraveyardTableName() = %q, expected %q", name, tt.tableName)
+			}
+			deletedAt, cluster, ok := models.ParseGraveyardTableName(tt.tableName)
+			if !ok || deletedAt != 1700000000 || cluster != tt.cluster {
+				t.Errorf("ParseGraveyardTableName(%q) = %d, %q, %v, expected 1700000000, %q, true", tt.tableName, deletedAt, cluster, ok, tt.cluster)
+			}
+		})
+	}
+}
+
+func TestParseGraveyardTableNameInvalid(t *testing.T) {
+	for _, name := range []string{"events", "db__events", "db__events__c_64617461", "db__events__1700000000__c_zz"} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, ok := models.ParseGraveyardTableName(name); ok {
+				t.Errorf("ParseGraveyardTableName(%q) is valid, expected invalid", name)
+			}
+		})
+	}
+}
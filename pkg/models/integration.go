@@ -0,0 +1,193 @@
+package models
+
+import (
+	"strings"
+)
+
+// IntegrationResource holds the arguments of the S3, S3Queue, AzureBlobStorage, URL and File
+// engine tables, reading and writing data stored outside of Clickhouse
+type IntegrationResource struct {
+	NamedCollection string
+	// Endpoint is the connection string or the storage account URL of AzureBlobStorage tables
+	Endpoint  string
+	Container string
+	Path      string
+	Format    string
+	// Compression is detected from the path extension when empty
+	Compression string
+	// AccessKeyID and SecretAccessKey are the account name and key of AzureBlobStorage tables
+	AccessKeyID     string
+	SecretAccessKey string
+	Mode            string
+	AfterProcessing string
+	KeeperPath      string
+}
+
+// IntegrationEngines are the engines defined with an IntegrationResource
+var IntegrationEngines = []string{"S3", "S3Queue", "AzureBlobStorage", "URL", "File"}
+
+// PartitionWildcard is replaced by the partition id in the path of the partitioned tables
+const PartitionWildcard = "{_partition_id}"
+
+// the keys overriding the named collection values, by engine
+var integrationNamedCollectionKeys = map[string]map[string]string{
+	"S3":      {"path": "url", "format": "format", "compression": "compression_method", "access_key_id": "access_key_id", "secret_access_key": "secret_access_key"},
+	"S3Queue": {"path": "url", "format": "format", "compression": "compression_method", "access_key_id": "access_key_id", "secret_access_key": "secret_access_key"},
+	"URL":     {"path": "url", "format": "format", "compression": "compression_method"},
+	"AzureBlobStorage": {
+		"container":         "container",
+		"path":              "blob_path",
+		"format":            "format",
+		"compression":       "compression",
+		"access_key_id":     "account_name",
+		"secret_access_key": "account_key",
+	},
+}
+
+// EngineParams returns the params of the engine, either positional or overriding the named collection
+func (i *IntegrationResource) EngineParams(engine string) []string {
+	if i.NamedCollection != "" {
+		return i.namedCollectionParams(engine)
+	}
+
+	var params []string
+	switch engine {
+	case "S3", "S3Queue":
+		params = append(params, quoteEngineParam(i.Path))
+		if i.AccessKeyID != "" {
+			params = append(params, quoteEngineParam(i.AccessKeyID), quoteEngineParam(i.SecretAccessKey))
+		}
+	case "AzureBlobStorage":
+		params = append(params, quoteEngineParam(i.Endpoint), quoteEngineParam(i.Container), quoteEngineParam(i.Path))
+		if i.AccessKeyID != "" {
+			params = append(params, quoteEngineParam(i.AccessKeyID), quoteEngineParam(i.SecretAccessKey))
+		}
+	case "URL":
+		params = append(params, quoteEngineParam(i.Path))
+	}
+	if i.Format != "" {
+		params = append(params, quoteEngineParam(i.Format))
+	}
+	if i.Compression != "" {
+		params = append(params, quoteEngineParam(i.Compression))
+	}
+	// the format of File tables is an identifier
+	if engine == "File" && len(params) > 0 {
+		params[0] = i.Format
+	}
+	return params
+}
+
+func (i *IntegrationResource) namedCollectionParams(engine string) []string {
+	params := []string{i.NamedCollection}
+	keys := integrationNamedCollectionKeys[engine]
+	values := []struct {
+		attribute string
+		value     string
+	}{
+		{"container", i.Container},
+		{"path", i.Path},
+		{"access_key_id", i.AccessKeyID},
+		{"secret_access_key", i.SecretAccessKey},
+		{"format", i.Format},
+		{"compression", i.Compression},
+	}
+	for _, value := range values {
+		if key, ok := keys[value.attribute]; ok && value.value != "" {
+			params = append(params, key+" = "+quoteEngineParam(value.value))
+		}
+	}
+	if i.Endpoint != "" {
+		params = append(params, azureEndpointKey(i.Endpoint)+" = "+quoteEngineParam(i.Endpoint))
+	}
+	return params
+}
+
+func azureEndpointKey(endpoint string) string {
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return "storage_account_url"
+	}
+	return "connection_string"
+}
+
+// Settings returns the S3Queue engine settings
+func (i *IntegrationResource) Settings() map[string]string {
+	settings := map[string]string{
+		"s3queue_mode":             i.Mode,
+		"s3queue_after_processing": i.AfterProcessing,
+		"s3queue_keeper_path":      i.KeeperPath,
+	}
+	for key, value := range settings {
+		if value == "" {
+			delete(settings, key)
+		}
+	}
+	return settings
+}
+
+// GetIntegrationResource maps the engine params and settings of an integration engine table.
+// The positional params are ambiguous, so they are read following the layout of the state. The
+// credentials are hidden by Clickhouse, they are always kept from the state.
+func GetIntegrationResource(engine string, engineParams []string, settings map[string]string, state *IntegrationResource) *IntegrationResource {
+	integration := IntegrationResource{
+		Endpoint:        state.Endpoint,
+		AccessKeyID:     state.AccessKeyID,
+		SecretAccessKey: state.SecretAccessKey,
+		Mode:            settings["s3queue_mode"],
+		AfterProcessing: settings["s3queue_after_processing"],
+		KeeperPath:      settings["s3queue_keeper_path"],
+	}
+
+	if state.NamedCollection != "" {
+		integration.NamedCollection = unquoteEngineParam(firstOrEmpty(engineParams))
+		attributes := make(map[string]string)
+		for attribute, key := range integrationNamedCollectionKeys[engine] {
+			attributes[key] = attribute
+		}
+		for _, param := range engineParams[min(1, len(engineParams)):] {
+			key, value, found := strings.Cut(param, "=")
+			if !found {
+				continue
+			}
+			switch attributes[strings.TrimSpace(key)] {
+			case "container":
+				integration.Container = unquoteEngineParam(strings.TrimSpace(value))
+			case "path":
+				integration.Path = unquoteEngineParam(strings.TrimSpace(value))
+			case "format":
+				integration.Format = unquoteEngineParam(strings.TrimSpace(value))
+			case "compression":
+				integration.Compression = unquoteEngineParam(strings.TrimSpace(value))
+			}
+		}
+		return &integration
+	}
+
+	params := engineParams
+	switch engine {
+	case "S3", "S3Queue", "URL":
+		integration.Path = unquoteEngineParam(firstOrEmpty(params))
+		params = params[min(1, len(params)):]
+	case "AzureBlobStorage":
+		if len(params) >= 3 {
+			integration.Container = unquoteEngineParam(params[1])
+			integration.Path = unquoteEngineParam(params[2])
+			params = params[3:]
+		}
+	}
+	if engine != "URL" && engine != "File" && state.AccessKeyID != "" {
+		params = params[min(2, len(params)):]
+	}
+	integration.Format = unquoteEngineParam(firstOrEmpty(params))
+	if len(params) > 1 {
+		integration.Compression = unquoteEngineParam(params[1])
+	}
+	return &integration
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
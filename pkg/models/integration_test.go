@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestIntegrationEngineParams(t *testing.T) {
+	testCases := []struct {
+		engine      string
+		integration models.IntegrationResource
+		expected    []string
+	}{
+		{
+			"S3",
+			models.IntegrationResource{Path: "https://bucket.s3.amazonaws.com/data/{_partition_id}.parquet", AccessKeyID: "key", SecretAccessKey: "secret", Format: "Parquet"},
+			[]string{"'https://bucket.s3.amazonaws.com/data/{_partition_id}.parquet'", "'key'", "'secret'", "'Parquet'"},
+		},
+		{
+			"S3Queue",
+			models.IntegrationResource{NamedCollection: "s3_credentials", Path: "https://bucket.s3.amazonaws.com/events/*.csv", Format: "CSVWithNames"},
+			[]string{"s3_credentials", "url = 'https://bucket.s3.amazonaws.com/events/*.csv'", "format = 'CSVWithNames'"},
+		},
+		{
+			"File",
+			models.IntegrationResource{Format: "TabSeparated", Compression: "gzip"},
+			[]string{"TabSeparated", "'gzip'"},
+		},
+	}
+
+	for _, tt := range testCases {
+		engineParams := tt.integration.EngineParams(tt.engine)
+		if !reflect.DeepEqual(engineParams, tt.expected) {
+			t.Errorf("EngineParams(%q) = %#v, expected %#v", tt.engine, engineParams, tt.expected)
+			continue
+		}
+		// the server hides the credentials
+		shown := make([]string, len(engineParams))
+		copy(shown, engineParams)
+		if tt.integration.AccessKeyID != "" && tt.integration.NamedCollection == "" {
+			shown[2] = "'[HIDDEN]'"
+		}
+		if parsed := models.GetIntegrationResource(tt.engine, shown, nil, &tt.integration); !reflect.DeepEqual(*parsed, tt.integration) {
+			t.Errorf("GetIntegrationResource(%q, %#v) = %#v, expected %#v", tt.engine, shown, *parsed, tt.integration)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// KafkaResource holds the typed settings of a Kafka engine table
+type KafkaResource struct {
+	BrokerList       string
+	TopicList        []string
+	GroupName        string
+	Format           string
+	NumConsumers     int
+	Schema           string
+	HandleErrorMode  string
+	SecurityProtocol string
+	SASLMechanism    string
+	SASLUsername     string
+	SASLPassword     string
+}
+
+// KafkaAlterableSettings are the Kafka engine settings that can be changed with ALTER TABLE
+// MODIFY SETTING. The topics, the consumer group and the message format define what the
+// table consumes, changing them replaces the table.
+var KafkaAlterableSettings = []string{
+	"kafka_broker_list",
+	"kafka_num_consumers",
+	"kafka_handle_error_mode",
+	"kafka_security_protocol",
+	"kafka_sasl_mechanism",
+	"kafka_sasl_username",
+	"kafka_sasl_password",
+}
+
+// KafkaSensitiveSettings are hidden by Clickhouse in the table definition
+var KafkaSensitiveSettings = []string{"kafka_sasl_username", "kafka_sasl_password"}
+
+// Settings returns the engine settings of the table, the empty ones are left to their default
+func (k *KafkaResource) Settings() map[string]string {
+	settings := map[string]string{
+		"kafka_broker_list":       k.BrokerList,
+		"kafka_topic_list":        strings.Join(k.TopicList, ","),
+		"kafka_group_name":        k.GroupName,
+		"kafka_format":            k.Format,
+		"kafka_schema":            k.Schema,
+		"kafka_handle_error_mode": k.HandleErrorMode,
+		"kafka_security_protocol": k.SecurityProtocol,
+		"kafka_sasl_mechanism":    k.SASLMechanism,
+		"kafka_sasl_username":     k.SASLUsername,
+		"kafka_sasl_password":     k.SASLPassword,
+	}
+	if k.NumConsumers > 0 {
+		settings["kafka_num_consumers"] = strconv.Itoa(k.NumConsumers)
+	}
+	for key, value := range settings {
+		if value == "" {
+			delete(settings, key)
+		}
+	}
+	return settings
+}
+
+// GetKafkaResource maps the engine settings of a Kafka table
+func GetKafkaResource(settings map[string]string) *KafkaResource {
+	kafka := KafkaResource{
+		BrokerList:       settings["kafka_broker_list"],
+		GroupName:        settings["kafka_group_name"],
+		Format:           settings["kafka_format"],
+		Schema:           settings["kafka_schema"],
+		HandleErrorMode:  settings["kafka_handle_error_mode"],
+		SecurityProtocol: settings["kafka_security_protocol"],
+		SASLMechanism:    settings["kafka_sasl_mechanism"],
+		SASLUsername:     settings["kafka_sasl_username"],
+		SASLPassword:     settings["kafka_sasl_password"],
+	}
+	for _, topic := range strings.Split(settings["kafka_topic_list"], ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			kafka.TopicList = append(kafka.TopicList, topic)
+		}
+	}
+	kafka.NumConsumers, _ = strconv.Atoi(settings["kafka_num_consumers"])
+	return &kafka
+}
@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestKafkaSettings(t *testing.T) {
+	kafka := models.KafkaResource{
+		BrokerList:   "kafka:9092",
+		TopicList:    []string{"events", "clicks"},
+		GroupName:    "clickhouse",
+		Format:       "JSONEachRow",
+		NumConsumers: 2,
+	}
+	expected := map[string]string{
+		"kafka_broker_list":   "kafka:9092",
+		"kafka_topic_list":    "events,clicks",
+		"kafka_group_name":    "clickhouse",
+		"kafka_format":        "JSONEachRow",
+		"kafka_num_consumers": "2",
+	}
+	settings := kafka.Settings()
+	if !reflect.DeepEqual(settings, expected) {
+		t.Fatalf("Settings() = %#v, expected %#v", settings, expected)
+	}
+	if parsed := models.GetKafkaResource(settings); !reflect.DeepEqual(*parsed, kafka) {
+		t.Errorf("GetKafkaResource(%#v) = %#v, expected %#v", settings, *parsed, kafka)
+	}
+}
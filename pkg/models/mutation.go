@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CHMutation is the status of a mutation of a MergeTree table, rewriting its parts in background
+type CHMutation struct {
+	MutationID       string `ch:"mutation_id"`
+	Command          string `ch:"command"`
+	IsDone           uint8  `ch:"is_done"`
+	PartsToDo        int64  `ch:"parts_to_do"`
+	LatestFailReason string `ch:"latest_fail_reason"`
+}
+
+// Done returns whether all the parts of the table have been mutated
+func (m *CHMutation) Done() bool {
+	return m.IsDone == 1
+}
+
+// Failing returns whether the last attempt to mutate a part failed. Clickhouse retries the
+// mutation until it's killed, so a failing mutation may still succeed.
+func (m *CHMutation) Failing() bool {
+	return !m.Done() && m.LatestFailReason != ""
+}
+
+// MatchesCommand returns whether the mutation was spawned by the given ALTER commands. Clickhouse
+// formats the commands again, so they are compared regardless of case, spaces, backticks and
+// parentheses.
+func (m *CHMutation) MatchesCommand(command string) bool {
+	return normalizeMutationCommand(m.Command) == normalizeMutationCommand(command)
+}
+
+func normalizeMutationCommand(command string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '`' || r == '(' || r == ')' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, command)
+}
+
+// MutationFailures tracks the failing mutations across the polls of their status. A mutation is
+// only considered failed once it fails with the same reason for a number of consecutive polls, as
+// a single failed attempt may be transient.
+type MutationFailures struct {
+	polls   int
+	reasons map[string]string
+	counts  map[string]int
+}
+
+func NewMutationFailures(polls int) *MutationFailures {
+	return &MutationFailures{polls: polls, reasons: make(map[string]string), counts: make(map[string]int)}
+}
+
+// Failed records the status of a mutation and returns whether its failure persisted
+func (f *MutationFailures) Failed(mutation CHMutation) bool {
+	if !mutation.Failing() {
+		delete(f.reasons, mutation.MutationID)
+		delete(f.counts, mutation.MutationID)
+		return false
+	}
+	if f.reasons[mutation.MutationID] != mutation.LatestFailReason {
+		f.reasons[mutation.MutationID] = mutation.LatestFailReason
+		f.counts[mutation.MutationID] = 0
+	}
+	f.counts[mutation.MutationID]++
+	return f.counts[mutation.MutationID] >= f.polls
+}
@@ -0,0 +1,80 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestMutationStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutation models.CHMutation
+		done     bool
+		failing  bool
+	}{
+		{"running", models.CHMutation{PartsToDo: 3}, false, false},
+		{"done", models.CHMutation{IsDone: 1}, true, false},
+		{"failing", models.CHMutation{PartsToDo: 3, LatestFailReason: "Code: 6. Cannot parse string 'a' as UInt64"}, false, true},
+		// a part failed before the mutation eventually succeeded
+		{"done after a failure", models.CHMutation{IsDone: 1, LatestFailReason: "Code: 241. Memory limit exceeded"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if done := tt.mutation.Done(); done != tt.done {
+				t.Errorf("Done() = %v, expected %v", done, tt.done)
+			}
+			if failing := tt.mutation.Failing(); failing != tt.failing {
+				t.Errorf("Failing() = %v, expected %v", failing, tt.failing)
+			}
+		})
+	}
+}
+
+func TestMutationMatchesCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		query    string
+		expected bool
+	}{
+		{"same command", "MODIFY COLUMN x String", "MODIFY COLUMN x String", true},
+		{"formatted by Clickhouse", "MODIFY COLUMN `x` Nullable(String) DEFAULT 1 + 1", "modify column x  Nullable(String) DEFAULT 1+1  ", true},
+		{"other column", "DROP COLUMN y", "DROP COLUMN x", false},
+		{"other command", "DROP COLUMN x", "MODIFY COLUMN x String", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutation := models.CHMutation{Command: tt.command}
+			if matches := mutation.MatchesCommand(tt.query); matches != tt.expected {
+				t.Errorf("MatchesCommand(%q) = %v, expected %v", tt.query, matches, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMutationFailures(t *testing.T) {
+	failing := func(reason string) models.CHMutation {
+		return models.CHMutation{MutationID: "0000000001", PartsToDo: 3, LatestFailReason: reason}
+	}
+	tests := []struct {
+		name     string
+		polls    []models.CHMutation
+		expected []bool
+	}{
+		{"persisting failure", []models.CHMutation{failing("a"), failing("a"), failing("a")}, []bool{false, false, true}},
+		{"changing reason", []models.CHMutation{failing("a"), failing("a"), failing("b"), failing("b")}, []bool{false, false, false, false}},
+		{"recovered", []models.CHMutation{failing("a"), failing("a"), {MutationID: "0000000001", PartsToDo: 2}, failing("a")}, []bool{false, false, false, false}},
+		{"done", []models.CHMutation{failing("a"), {MutationID: "0000000001", IsDone: 1, LatestFailReason: "a"}}, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := models.NewMutationFailures(3)
+			for i, mutation := range tt.polls {
+				if failed := failures.Failed(mutation); failed != tt.expected[i] {
+					t.Errorf("poll %d: Failed() = %v, expected %v", i+1, failed, tt.expected[i])
+				}
+			}
+		})
+	}
+}
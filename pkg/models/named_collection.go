@@ -0,0 +1,18 @@
+package models
+
+type NamedCollectionResource struct {
+	Name    string
+	Cluster string
+	Params  []NamedCollectionParam
+}
+
+type NamedCollectionParam struct {
+	Key         string
+	Value       string
+	Overridable bool
+}
+
+type CHNamedCollection struct {
+	Name string   `ch:"name"`
+	Keys []string `ch:"keys"`
+}
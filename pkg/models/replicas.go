@@ -0,0 +1,14 @@
+package models
+
+import "fmt"
+
+// HostDivergence is a host of the cluster where the definition of a resource differs from the
+// one of the connected node
+type HostDivergence struct {
+	Host   string
+	Reason string
+}
+
+func (h HostDivergence) String() string {
+	return fmt.Sprintf("%s: %s", h.Host, h.Reason)
+}
@@ -5,14 +5,18 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 )
 
 type CHTable struct {
 	Database   string     `ch:"database"`
 	Name       string     `ch:"name"`
+	UUID       string     `ch:"uuid"`
 	EngineFull string     `ch:"engine_full"`
 	SortingKey string     `ch:"sorting_key"`
+	PrimaryKey string     `ch:"primary_key"`
+	SampleBy   string     `ch:"sampling_key"`
 	Engine     string     `ch:"engine"`
 	Comment    string     `ch:"comment"`
 	Columns    []CHColumn `ch:"columns"`
@@ -47,11 +51,71 @@ type TableResource struct {
 	EngineParams []string
 	PrimaryKey   []string
 	OrderBy      []string
+	SampleBy     string
 	Columns      []ColumnDefinition
 	PartitionBy  []PartitionByResource
 	Indexes      []IndexDefinition
 	Settings     map[string]string
 	TTL          map[string]string
+	// ZooKeeperPath and ReplicaName are the first params of Replicated engines
+	ZooKeeperPath string
+	ReplicaName   string
+	Distributed   *DistributedResource
+	Kafka         *KafkaResource
+	Integration   *IntegrationResource
+	// AsTable is the table the columns are copied from when none is defined
+	AsTable string
+}
+
+// DistributedResource defines the tables of a cluster a Distributed table reads from and writes to
+type DistributedResource struct {
+	Cluster     string
+	Database    string
+	Table       string
+	ShardingKey string
+	PolicyName  string
+}
+
+// EngineParams returns the params of the Distributed engine
+func (t *DistributedResource) EngineParams() []string {
+	params := []string{quoteEngineParam(t.Cluster), quoteEngineParam(t.Database), quoteEngineParam(t.Table)}
+	if t.ShardingKey != "" {
+		params = append(params, t.ShardingKey)
+	}
+	if t.PolicyName != "" {
+		params = append(params, quoteEngineParam(t.PolicyName))
+	}
+	return params
+}
+
+// GetDistributedResource maps the params of a Distributed engine
+func GetDistributedResource(engineParams []string) *DistributedResource {
+	if len(engineParams) < 3 {
+		return nil
+	}
+	distributed := DistributedResource{
+		Cluster:  unquoteEngineParam(engineParams[0]),
+		Database: unquoteEngineParam(engineParams[1]),
+		Table:    unquoteEngineParam(engineParams[2]),
+	}
+	if len(engineParams) > 3 {
+		distributed.ShardingKey = engineParams[3]
+	}
+	if len(engineParams) > 4 {
+		distributed.PolicyName = unquoteEngineParam(engineParams[4])
+	}
+	return &distributed
+}
+
+func quoteEngineParam(param string) string {
+	if strings.HasPrefix(param, "'") {
+		return param
+	}
+	return "'" + param + "'"
+}
+
+func unquoteEngineParam(param string) string {
+	return strings.Trim(param, "'")
 }
 
 type IndexDefinition struct {
@@ -106,41 +170,107 @@ func (t *CHTable) ToResource() (*TableResource, error) {
 		Name:         t.Name,
 		EngineFull:   t.EngineFull,
 		Engine:       t.Engine,
-		EngineParams: removeDefaultParams(GetEngineParams(t.EngineFull)),
+		EngineParams: GetEngineParams(t.EngineFull),
 		OrderBy:      GetOrderBy(t.SortingKey),
+		PrimaryKey:   GetOrderBy(t.PrimaryKey),
+		SampleBy:     t.SampleBy,
 		Columns:      t.ColumnsToResource(),
 		Indexes:      t.IndexesToResource(),
 		Comment:      t.Comment,
+		Settings:     GetEngineSettings(t.EngineFull),
+	}
+	if IsReplicatedEngine(t.Engine) && len(tableResource.EngineParams) >= 2 && isQuoted(tableResource.EngineParams[0]) {
+		tableResource.ZooKeeperPath = unquoteEngineParam(tableResource.EngineParams[0])
+		tableResource.ReplicaName = unquoteEngineParam(tableResource.EngineParams[1])
+		tableResource.EngineParams = tableResource.EngineParams[2:]
 	}
 
 	return &tableResource, nil
 }
 
+var engineNameRegexp = regexp.MustCompile(`^\w+$`)
+
 func GetEngineParams(engineFull string) []string {
-	r := regexp.MustCompile(`^\w+\(([^)]*)\)`)
-	match := r.FindStringSubmatch(engineFull)
-	var engineParams []string
-	if len(match) > 1 {
-		values := strings.Split(match[1], ",")
-		for _, value := range values {
-			value = strings.TrimSpace(value)
-			engineParams = append(engineParams, strings.TrimSpace(value))
+	// the params may hold function calls, like the sharding key of Distributed tables
+	open := strings.IndexByte(engineFull, '(')
+	if open <= 0 || !engineNameRegexp.MatchString(engineFull[:open]) {
+		return nil
+	}
+	closing := common.FindClosingParenthesis(engineFull, open)
+	if closing < 0 {
+		return nil
+	}
+	return common.SplitTopLevel(engineFull[open+1 : closing])
+}
+
+// GetEngineSettings returns the settings of the table definition, with the string values unquoted
+func GetEngineSettings(engineFull string) map[string]string {
+	start := common.FindTopLevelKeyword(engineFull, "SETTINGS", 0)
+	if start < 0 {
+		return nil
+	}
+	settings := make(map[string]string)
+	for _, setting := range common.SplitTopLevel(engineFull[start+len("SETTINGS"):]) {
+		key, value, found := strings.Cut(setting, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+			value = strings.NewReplacer(`\'`, "'", `\\`, `\`).Replace(value[1 : len(value)-1])
 		}
+		settings[strings.TrimSpace(key)] = value
 	}
-	return engineParams
+	return settings
 }
 
 func GetOrderBy(sortingKey string) []string {
-	var orderBy []string
-	sortingKey = strings.TrimSpace(sortingKey)
+	return common.SplitTopLevel(strings.TrimSpace(sortingKey))
+}
+
+// GetOrderByExtension returns the expressions appended at the end of the sorting key,
+// when the new sorting key only extends the old one
+func GetOrderByExtension(oldOrderBy []string, newOrderBy []string) ([]string, bool) {
+	if len(newOrderBy) < len(oldOrderBy) {
+		return nil, false
+	}
+	for i, key := range oldOrderBy {
+		if newOrderBy[i] != key {
+			return nil, false
+		}
+	}
+	return newOrderBy[len(oldOrderBy):], true
+}
 
-	keys := strings.Split(sortingKey, ",")
+// IsReplicatedEngine returns whether the engine is a Replicated MergeTree engine
+func IsReplicatedEngine(engine string) bool {
+	return strings.HasPrefix(engine, "Replicated") && strings.HasSuffix(engine, "MergeTree")
+}
 
-	for _, key := range keys {
-		key = strings.TrimSpace(key)
-		orderBy = append(orderBy, key)
+// ReplicationParams returns the ZooKeeper path and replica name params, Clickhouse uses the
+// default_replica_path and default_replica_name settings when they are not defined
+func (t *TableResource) ReplicationParams() []string {
+	if t.ZooKeeperPath == "" {
+		return nil
 	}
-	return orderBy
+	return []string{quoteEngineParam(t.ZooKeeperPath), quoteEngineParam(t.ReplicaName)}
+}
+
+var macroRegexp = regexp.MustCompile(`\{(\w+)\}`)
+
+// ExpandMacros substitutes the {macro} placeholders of a ZooKeeper path or replica name, the
+// unknown macros are kept
+func ExpandMacros(value string, macros map[string]string) string {
+	return macroRegexp.ReplaceAllStringFunc(value, func(placeholder string) string {
+		if substitution, ok := macros[placeholder[1:len(placeholder)-1]]; ok {
+			return substitution
+		}
+		return placeholder
+	})
+}
+
+func isQuoted(param string) bool {
+	return len(param) >= 2 && strings.HasPrefix(param, "'") && strings.HasSuffix(param, "'")
 }
 
 // without this, terraform sees a diff for Replicated tables
@@ -169,15 +299,62 @@ func (t *TableResource) GetColumnsResourceList() []ColumnDefinition {
 	return columnResources
 }
 
+// CollapseNestedColumns merges back the sub-columns Clickhouse reports for flattened Nested
+// columns (n.a Array(String), n.b Array(UInt8)) into a single Nested(a String, b UInt8) column
+func (t *TableResource) CollapseNestedColumns(nestedColumns []string) {
+	if len(nestedColumns) == 0 {
+		return
+	}
+
+	nestedFields := make(map[string][]string)
+	var columns []ColumnDefinition
+	for _, column := range t.Columns {
+		parent, field, isSubColumn := strings.Cut(column.Name, ".")
+		if !isSubColumn || !common.Contains(nestedColumns, parent) {
+			columns = append(columns, column)
+			continue
+		}
+
+		if _, exists := nestedFields[parent]; !exists {
+			columns = append(columns, ColumnDefinition{Name: parent, Comment: column.Comment})
+		}
+		fieldType := strings.TrimSuffix(strings.TrimPrefix(column.Type, "Array("), ")")
+		nestedFields[parent] = append(nestedFields[parent], fmt.Sprintf("%s %s", field, fieldType))
+	}
+
+	for i, column := range columns {
+		if fields, exists := nestedFields[column.Name]; exists {
+			columns[i].Type = fmt.Sprintf("Nested(%s)", strings.Join(fields, ", "))
+		}
+	}
+	t.Columns = columns
+}
+
+// KeepObjectColumnTypes keeps the JSON and Object types of the state for the columns Clickhouse
+// reports with their expanded Tuple type
+func (t *TableResource) KeepObjectColumnTypes(stateTypes map[string]string) {
+	for i, column := range t.Columns {
+		if stateType, ok := stateTypes[column.Name]; ok && IsExpandedObjectType(stateType, column.Type) {
+			t.Columns[i].Type = stateType
+		}
+	}
+}
+
 func (t *TableResource) SetPartitionBy(partitionBy []interface{}) {
+	t.PartitionBy = append(t.PartitionBy, GetPartitionBy(partitionBy)...)
+}
+
+func GetPartitionBy(partitionBy []interface{}) []PartitionByResource {
+	var partitionByResources []PartitionByResource
 	for _, partitionBy := range partitionBy {
 		partitionByResource := PartitionByResource{
 			By:                partitionBy.(map[string]interface{})["by"].(string),
 			PartitionFunction: partitionBy.(map[string]interface{})["partition_function"].(string),
 			Mod:               partitionBy.(map[string]interface{})["mod"].(string),
 		}
-		t.PartitionBy = append(t.PartitionBy, partitionByResource)
+		partitionByResources = append(partitionByResources, partitionByResource)
 	}
+	return partitionByResources
 }
 
 func (t *TableResource) HasColumn(columnName string) bool {
@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestGetEngineParams(t *testing.T) {
+	testCases := []struct {
+		engineFull string
+		expected   []string
+	}{
+		{"MergeTree ORDER BY key SETTINGS index_granularity = 8192", nil},
+		{"ReplacingMergeTree(version) ORDER BY key", []string{"version"}},
+		{"Distributed('default', 'db', 'local', cityHash64(user_id, 'a,b')) SETTINGS fsync_after_insert = 1", []string{"'default'", "'db'", "'local'", "cityHash64(user_id, 'a,b')"}},
+	}
+
+	for _, tt := range testCases {
+		if engineParams := models.GetEngineParams(tt.engineFull); !reflect.DeepEqual(engineParams, tt.expected) {
+			t.Errorf("GetEngineParams(%q) = %#v, expected %#v", tt.engineFull, engineParams, tt.expected)
+		}
+	}
+}
+
+func TestDistributedEngineParams(t *testing.T) {
+	distributed := models.DistributedResource{Cluster: "default", Database: "db", Table: "local", ShardingKey: "rand()", PolicyName: "default"}
+	engineParams := distributed.EngineParams()
+	expected := []string{"'default'", "'db'", "'local'", "rand()", "'default'"}
+	if !reflect.DeepEqual(engineParams, expected) {
+		t.Fatalf("EngineParams() = %#v, expected %#v", engineParams, expected)
+	}
+	if parsed := models.GetDistributedResource(engineParams); *parsed != distributed {
+		t.Errorf("GetDistributedResource(%#v) = %#v, expected %#v", engineParams, *parsed, distributed)
+	}
+}
+
+func TestGetEngineSettings(t *testing.T) {
+	engineFull := `Kafka SETTINGS kafka_broker_list = 'kafka:9092', kafka_topic_list = 'a,b', kafka_num_consumers = 2, kafka_schema = 'it\'s'`
+	expected := map[string]string{
+		"kafka_broker_list":   "kafka:9092",
+		"kafka_topic_list":    "a,b",
+		"kafka_num_consumers": "2",
+		"kafka_schema":        "it's",
+	}
+	if settings := models.GetEngineSettings(engineFull); !reflect.DeepEqual(settings, expected) {
+		t.Errorf("GetEngineSettings(%q) = %#v, expected %#v", engineFull, settings, expected)
+	}
+}
+
+func TestReplicatedTableToResource(t *testing.T) {
+	chTable := models.CHTable{
+		Engine:     "ReplicatedReplacingMergeTree",
+		EngineFull: "ReplicatedReplacingMergeTree('/clickhouse/tables/{shard}/db/events', '{replica}', version) ORDER BY key",
+	}
+	table, err := chTable.ToResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.ZooKeeperPath != "/clickhouse/tables/{shard}/db/events" || table.ReplicaName != "{replica}" || !reflect.DeepEqual(table.EngineParams, []string{"version"}) {
+		t.Errorf("ToResource() = %q, %q, %#v", table.ZooKeeperPath, table.ReplicaName, table.EngineParams)
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	macros := map[string]string{"shard": "01", "database": "db", "table": "events"}
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"/clickhouse/tables/{shard}/{database}/{table}", "/clickhouse/tables/01/db/events"},
+		{"/clickhouse/tables/{uuid}/{shard}", "/clickhouse/tables/{uuid}/01"},
+	}
+
+	for _, tt := range testCases {
+		if expanded := models.ExpandMacros(tt.value, macros); expanded != tt.expected {
+			t.Errorf("ExpandMacros(%q) = %q, expected %q", tt.value, expanded, tt.expected)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package models
 
-import "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+import (
+	"sort"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
 
 type ViewResource struct {
 	Database     string
@@ -10,14 +16,32 @@ type ViewResource struct {
 	Materialized bool
 	ToTable      string
 	Comment      string
+	Engine       *ViewEngineResource
+	Populate     bool
+	Refresh      *ViewRefreshResource
+	Columns      []ColumnDefinition
+	SQLSecurity  string
+	Definer      string
+}
+
+// ViewEngineResource defines the inner table of a materialized view without TO table
+type ViewEngineResource struct {
+	Engine       string
+	EngineParams []string
+	OrderBy      []string
+	PartitionBy  []PartitionByResource
+	PrimaryKey   []string
+	Settings     map[string]string
 }
 
 type CHView struct {
 	Database string `ch:"database"`
 	Name     string `ch:"name"`
+	UUID     string `ch:"uuid"`
 	Query    string `ch:"as_select"`
 	Engine   string `ch:"engine"`
 	Comment  string `ch:"comment"`
+	Create   string `ch:"create_table_query"`
 }
 
 func (t *CHView) ToResource() (*ViewResource, error) {
@@ -29,6 +53,14 @@ func (t *CHView) ToResource() (*ViewResource, error) {
 
 	viewResource.Comment = t.Comment
 	viewResource.Materialized = t.Engine == "MaterializedView"
+	viewResource.Refresh = ParseRefreshClause(t.Create)
+
+	// the rest of the definition is only available in the create query
+	definition := getViewDefinition(t.Create)
+	viewResource.ToTable = getViewClauseValue(definition, "TO")
+	viewResource.SQLSecurity = getViewClauseValue(definition, "SQL SECURITY")
+	viewResource.Definer = getViewDefiner(definition)
+	viewResource.Columns = getViewColumns(definition)
 
 	return &viewResource, nil
 }
@@ -36,5 +68,185 @@ func (t *CHView) ToResource() (*ViewResource, error) {
 func (t *ViewResource) Validate() diag.Diagnostics {
 	var diags diag.Diagnostics
 
+	if t.Engine != nil && !t.Materialized {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "engine can only be defined for materialized views",
+		})
+	}
+	if t.Populate && (t.Engine == nil || t.ToTable != "") {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "populate is only allowed for materialized views with an inner engine",
+		})
+	}
+
+	if t.Refresh != nil && !t.Materialized {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "refresh can only be defined for materialized views",
+		})
+	}
+	if t.Refresh != nil && t.Populate {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "populate is not allowed for refreshable materialized views",
+		})
+	}
+
 	return diags
 }
+
+// ToEngineResource maps the inner table of a materialized view to its engine definition
+func (t *CHTable) ToEngineResource() *ViewEngineResource {
+	return &ViewEngineResource{
+		Engine:       t.Engine,
+		EngineParams: removeDefaultParams(GetEngineParams(t.EngineFull)),
+		OrderBy:      GetOrderBy(t.SortingKey),
+		PrimaryKey:   GetOrderBy(t.PrimaryKey),
+	}
+}
+
+// ViewRefreshResource defines the schedule of a refreshable materialized view
+type ViewRefreshResource struct {
+	Every        string
+	After        string
+	Offset       string
+	RandomizeFor string
+	DependsOn    []string
+	Append       bool
+	Settings     map[string]string
+}
+
+type CHViewRefresh struct {
+	Status          string `ch:"status"`
+	NextRefreshTime string `ch:"next_refresh_time"`
+}
+
+var refreshClauseKeywords = []string{"EVERY", "AFTER", "OFFSET", "RANDOMIZE FOR", "DEPENDS ON", "SETTINGS", "APPEND"}
+
+// getViewDefinition returns the part of the create query of a view before its SELECT query
+func getViewDefinition(createQuery string) string {
+	if i := common.FindTopLevelKeyword(createQuery, "AS", 0); i >= 0 {
+		return createQuery[:i]
+	}
+	return createQuery
+}
+
+// getViewClauseValue returns the word following a keyword of the view definition, e.g. the
+// table of `TO db.table`
+func getViewClauseValue(definition string, keyword string) string {
+	i := common.FindTopLevelKeyword(definition, keyword, 0)
+	if i < 0 {
+		return ""
+	}
+	fields := strings.Fields(definition[i+len(keyword):])
+	if len(fields) == 0 {
+		return ""
+	}
+	value, _, _ := strings.Cut(fields[0], "(")
+	return strings.ReplaceAll(value, "`", "")
+}
+
+// getViewDefiner returns the user of `DEFINER = user`, not to be confused with `SQL SECURITY DEFINER`
+func getViewDefiner(definition string) string {
+	for i := common.FindTopLevelKeyword(definition, "DEFINER", 0); i >= 0; i = common.FindTopLevelKeyword(definition, "DEFINER", i+1) {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(definition[i+len("DEFINER"):]), "="); ok {
+			return getViewClauseValue("DEFINER "+value, "DEFINER")
+		}
+	}
+	return ""
+}
+
+// getViewColumns returns the column list of the view definition, which comes before the
+// inner engine definition
+func getViewColumns(definition string) []ColumnDefinition {
+	if i := common.FindTopLevelKeyword(definition, "ENGINE", 0); i >= 0 {
+		definition = definition[:i]
+	}
+	open := strings.IndexByte(definition, '(')
+	if open < 0 {
+		return nil
+	}
+	closing := common.FindClosingParenthesis(definition, open)
+	if closing < 0 {
+		return nil
+	}
+
+	var columns []ColumnDefinition
+	for _, column := range common.SplitTopLevel(definition[open+1 : closing]) {
+		var name, columnType string
+		if strings.HasPrefix(column, "`") {
+			end := strings.IndexByte(column[1:], '`')
+			if end < 0 {
+				continue
+			}
+			name, columnType = column[1:end+1], column[end+2:]
+		} else {
+			name, columnType, _ = strings.Cut(column, " ")
+		}
+		columns = append(columns, ColumnDefinition{Name: name, Type: strings.TrimSpace(columnType)})
+	}
+	return columns
+}
+
+// ParseRefreshClause extracts the REFRESH clause of the create query of a materialized view
+func ParseRefreshClause(createQuery string) *ViewRefreshResource {
+	// the clause is part of the view definition, before the SELECT query
+	createQuery = getViewDefinition(createQuery)
+	start := common.FindTopLevelKeyword(createQuery, "REFRESH", 0)
+	if start < 0 {
+		return nil
+	}
+	end := len(createQuery)
+	if columnsStart := strings.IndexByte(createQuery[start:], '('); columnsStart >= 0 {
+		end = start + columnsStart
+	}
+	for _, keyword := range []string{"TO", "ENGINE", "EMPTY", "POPULATE", "DEFINER", "SQL SECURITY", "COMMENT"} {
+		if i := common.FindTopLevelKeyword(createQuery, keyword, start); i >= 0 && i < end {
+			end = i
+		}
+	}
+	clause := createQuery[start+len("REFRESH") : end]
+
+	// split the clause in the sections introduced by each keyword
+	type section struct {
+		keyword string
+		start   int
+	}
+	var sections []section
+	for _, keyword := range refreshClauseKeywords {
+		if i := common.FindTopLevelKeyword(clause, keyword, 0); i >= 0 {
+			sections = append(sections, section{keyword: keyword, start: i})
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].start < sections[j].start })
+
+	refresh := ViewRefreshResource{}
+	for i, section := range sections {
+		sectionEnd := len(clause)
+		if i+1 < len(sections) {
+			sectionEnd = sections[i+1].start
+		}
+		value := strings.TrimSpace(clause[section.start+len(section.keyword) : sectionEnd])
+		switch section.keyword {
+		case "EVERY":
+			refresh.Every = value
+		case "AFTER":
+			refresh.After = value
+		case "OFFSET":
+			refresh.Offset = value
+		case "RANDOMIZE FOR":
+			refresh.RandomizeFor = value
+		case "DEPENDS ON":
+			refresh.DependsOn = common.SplitTopLevel(value)
+		case "APPEND":
+			refresh.Append = true
+		}
+	}
+	return &refresh
+}
@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func TestParseRefreshClause(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected *models.ViewRefreshResource
+	}{
+		{
+			"CREATE MATERIALIZED VIEW db.v TO db.t (`total` UInt64) AS SELECT count() AS total FROM db.refresh",
+			nil,
+		},
+		{
+			"CREATE MATERIALIZED VIEW db.v REFRESH EVERY 1 HOUR OFFSET 5 MINUTE TO db.t (`total` UInt64) AS SELECT count() AS total FROM db.events",
+			&models.ViewRefreshResource{Every: "1 HOUR", Offset: "5 MINUTE"},
+		},
+		{
+			"CREATE MATERIALIZED VIEW db.v REFRESH AFTER 30 MINUTE RANDOMIZE FOR 1 MINUTE DEPENDS ON db.a, db.b APPEND TO db.t (`total` UInt64) AS SELECT 1",
+			&models.ViewRefreshResource{After: "30 MINUTE", RandomizeFor: "1 MINUTE", DependsOn: []string{"db.a", "db.b"}, Append: true},
+		},
+	}
+
+	for _, tt := range testCases {
+		if result := models.ParseRefreshClause(tt.query); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("ParseRefreshClause(%q) = %+v, expected %+v", tt.query, result, tt.expected)
+		}
+	}
+}
+
+func TestViewToResource(t *testing.T) {
+	view := models.CHView{
+		Database: "db",
+		Name:     "v",
+		Engine:   "MaterializedView",
+		Create:   "CREATE MATERIALIZED VIEW db.v TO db.`totals` (`key` UInt64, `values` Map(String, UInt64)) DEFINER = admin SQL SECURITY DEFINER AS SELECT key, map('a', 1) AS values FROM db.events",
+	}
+
+	resource, err := view.ToResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.ToTable != "db.totals" {
+		t.Errorf("ToTable = %q, expected %q", resource.ToTable, "db.totals")
+	}
+	if resource.SQLSecurity != "DEFINER" || resource.Definer != "admin" {
+		t.Errorf("SQLSecurity, Definer = %q, %q, expected %q, %q", resource.SQLSecurity, resource.Definer, "DEFINER", "admin")
+	}
+	expectedColumns := []models.ColumnDefinition{{Name: "key", Type: "UInt64"}, {Name: "values", Type: "Map(String, UInt64)"}}
+	if !reflect.DeepEqual(resource.Columns, expectedColumns) {
+		t.Errorf("Columns = %+v, expected %+v", resource.Columns, expectedColumns)
+	}
+}
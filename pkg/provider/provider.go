@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
@@ -12,6 +13,7 @@ import (
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -70,16 +72,110 @@ func New(version string) func() *schema.Provider {
 					Optional:    true,
 					Default:     false,
 				},
+				"allow_drop": {
+					Description: "Allows tables and databases to be removed when their resources are destroyed or replaced",
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+				},
+				"drop_mode": {
+					Description:  "How tables and databases are removed: `drop` drops them, `detach` detaches them permanently so they can be attached back (tables are renamed with a `__detached__<timestamp>` suffix first, databases can't be replaced in this mode), `graveyard` renames the tables into the graveyard database (databases are dropped, as they must be empty)",
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      sdk.DropModeDrop,
+					ValidateFunc: validation.StringInSlice([]string{sdk.DropModeDrop, sdk.DropModeDetach, sdk.DropModeGraveyard}, false),
+				},
+				"graveyard_database": {
+					Description: "Database where the tables are moved to when `drop_mode` is `graveyard`",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "graveyard",
+				},
+				"graveyard_retention": {
+					Description:  "Duration the tables are kept in the graveyard database before being dropped (e.g. `168h`). Expired tables are purged whenever a table is moved to the graveyard. If not set, they are kept forever",
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateDuration,
+				},
+				"distributed_ddl_task_timeout": {
+					Description:  "Seconds the ON CLUSTER queries wait for all the hosts of the cluster. The hosts that don't finish in time execute the query in background",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      180,
+					ValidateFunc: validation.IntAtLeast(-1),
+				},
+				"distributed_ddl_output_mode": {
+					Description:  "What the ON CLUSTER queries return: `throw` fails when a host fails or doesn't finish in time, `null_status_on_timeout` and `never_throw` only fail when a host fails, reporting the unfinished hosts as warnings, `none` doesn't wait for the hosts. The `*_only_active` variants don't wait for the inactive hosts. The status of each host is reported when the query fails",
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      sdk.DistributedDDLOutputModeThrow,
+					ValidateFunc: validation.StringInSlice(sdk.DistributedDDLOutputModes, false),
+				},
+				"wait_for_mutations": {
+					Description: "Wait until the mutations spawned by the ALTER queries are done, like column type changes, column drops or TTL changes, within the update timeout of the resource. A mutation failing with the same reason for 3 consecutive polls, 2 seconds apart, is reported as failed with this reason. Only the mutations of the connected node are tracked",
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+				},
+				"kill_failed_mutations": {
+					Description: "Kill the mutations that fail while `wait_for_mutations` waits for them, otherwise Clickhouse keeps retrying them in background",
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+				},
+				"retry": {
+					Description: "How the DDL queries failing with a transient error (e.g. `TOO_MANY_SIMULTANEOUS_QUERIES`, a network reset or a Keeper session expiration) are retried. By default they are attempted 3 times. Network errors and timeouts are only retried for the queries guarded by `IF [NOT] EXISTS`, and renames, table exchanges, sorting key changes and data copies are never retried, as they may have been applied",
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"max_attempts": {
+								Description:  "Number of times a query is attempted, `1` disables the retries",
+								Type:         schema.TypeInt,
+								Optional:     true,
+								Default:      sdk.DefaultRetryPolicy.MaxAttempts,
+								ValidateFunc: validation.IntAtLeast(1),
+							},
+							"initial_backoff": {
+								Description:  "Duration waited before the first retry, it doubles with every attempt",
+								Type:         schema.TypeString,
+								Optional:     true,
+								Default:      sdk.DefaultRetryPolicy.InitialBackoff.String(),
+								ValidateFunc: validateDuration,
+							},
+							"max_backoff": {
+								Description:  "Maximum duration waited between two attempts",
+								Type:         schema.TypeString,
+								Optional:     true,
+								Default:      sdk.DefaultRetryPolicy.MaxBackoff.String(),
+								ValidateFunc: validateDuration,
+							},
+						},
+					},
+				},
+				"settings": {
+					Description:  "Settings applied to all the queries of the provider, e.g. `alter_sync`, `mutations_sync` or `allow_experimental_*` flags. `max_execution_time` defaults to `300`. The `distributed_ddl_*` settings have their own attributes",
+					Type:         schema.TypeMap,
+					Optional:     true,
+					ValidateFunc: validateSettings,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
 				"clickhouse_dbs": datasources.DataSourceDbs(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"clickhouse_db":    resources.ResourceDb(),
-				"clickhouse_table": resources.ResourceTable(),
-				"clickhouse_view":  resources.ResourceView(),
-				"clickhouse_role":  resources.ResourceRole(),
-				"clickhouse_user":  resources.ResourceUser(),
+				"clickhouse_db":               resources.ResourceDb(),
+				"clickhouse_table":            resources.ResourceTable(),
+				"clickhouse_view":             resources.ResourceView(),
+				"clickhouse_dictionary":       resources.ResourceDictionary(),
+				"clickhouse_function":         resources.ResourceFunction(),
+				"clickhouse_named_collection": resources.ResourceNamedCollection(),
+				"clickhouse_role":             resources.ResourceRole(),
+				"clickhouse_user":             resources.ResourceUser(),
 			},
 			ConfigureContextFunc: configure(),
 		}
@@ -94,6 +190,42 @@ func configure() func(context.Context, *schema.ResourceData) (any, diag.Diagnost
 		password := d.Get("password").(string)
 		secure := d.Get("secure").(bool)
 
+		dropPolicy := sdk.DropPolicy{
+			AllowDrop:         d.Get("allow_drop").(bool),
+			Mode:              d.Get("drop_mode").(string),
+			GraveyardDatabase: d.Get("graveyard_database").(string),
+		}
+		if retention := d.Get("graveyard_retention").(string); retention != "" {
+			dropPolicy.GraveyardRetention, _ = time.ParseDuration(retention)
+		}
+
+		distributedDDL := sdk.DistributedDDL{
+			TaskTimeout: d.Get("distributed_ddl_task_timeout").(int),
+			OutputMode:  d.Get("distributed_ddl_output_mode").(string),
+		}
+
+		mutationPolicy := sdk.MutationPolicy{
+			Wait:          d.Get("wait_for_mutations").(bool),
+			KillOnFailure: d.Get("kill_failed_mutations").(bool),
+		}
+
+		retryPolicy := sdk.DefaultRetryPolicy
+		if retry := d.Get("retry").([]interface{}); len(retry) > 0 && retry[0] != nil {
+			retryMap := retry[0].(map[string]interface{})
+			retryPolicy.MaxAttempts = retryMap["max_attempts"].(int)
+			retryPolicy.InitialBackoff, _ = time.ParseDuration(retryMap["initial_backoff"].(string))
+			retryPolicy.MaxBackoff, _ = time.ParseDuration(retryMap["max_backoff"].(string))
+		}
+
+		settings := clickhouse.Settings{
+			"max_execution_time": 300,
+		}
+		for key, value := range common.MapInterfaceToMapOfString(d.Get("settings").(map[string]interface{})) {
+			settings[key] = value
+		}
+		settings["distributed_ddl_task_timeout"] = distributedDDL.TaskTimeout
+		settings["distributed_ddl_output_mode"] = distributedDDL.OutputMode
+
 		var TLSConfig *tls.Config
 		// To use TLS it's necessary to set the TLSConfig field as not nil
 		if secure {
@@ -113,10 +245,8 @@ func configure() func(context.Context, *schema.ResourceData) (any, diag.Diagnost
 					fmt.Printf(format, v...)
 				}
 			},
-			Settings: clickhouse.Settings{
-				"max_execution_time": 300,
-			},
-			TLS: TLSConfig,
+			Settings: settings,
+			TLS:      TLSConfig,
 		})
 
 		var diags diag.Diagnostics
@@ -129,6 +259,24 @@ func configure() func(context.Context, *schema.ResourceData) (any, diag.Diagnost
 			return nil, diag.FromErr(fmt.Errorf("ping clickhouse database: %w", err))
 		}
 
-		return &sdk.Client{Conn: conn}, diags
+		return &sdk.Client{Conn: conn, DropPolicy: dropPolicy, DistributedDDL: distributedDDL, RetryPolicy: retryPolicy, MutationPolicy: mutationPolicy}, diags
+	}
+}
+
+func validateDuration(value interface{}, key string) ([]string, []error) {
+	if _, err := time.ParseDuration(value.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q must be a duration like 24h or 30m: %v", key, err)}
+	}
+	return nil, nil
+}
+
+// the distributed DDL settings change how the queries ON CLUSTER are reported, they can only be
+// set with their attributes
+func validateSettings(value interface{}, key string) ([]string, []error) {
+	for setting := range value.(map[string]interface{}) {
+		if setting == "distributed_ddl_task_timeout" || setting == "distributed_ddl_output_mode" {
+			return nil, []error{fmt.Errorf("%q can't hold %s, use the %s attribute of the provider", key, setting, setting)}
+		}
 	}
+	return nil, nil
 }
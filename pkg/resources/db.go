@@ -5,14 +5,18 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
 
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+var databaseEngines = []string{"Atomic", "Ordinary", "Replicated", "Lazy", "MySQL", "PostgreSQL", "MaterializedPostgreSQL", "SQLite"}
+
 func ResourceDb() *schema.Resource {
 	return &schema.Resource{
 		// This description is used by the documentation generator and the language server.
@@ -21,8 +25,12 @@ func ResourceDb() *schema.Resource {
 		CreateContext: resourceDbCreate,
 		ReadContext:   resourceDbRead,
 		DeleteContext: resourceDbDelete,
+		UpdateContext: resourceDbUpdate,
+		CustomizeDiff: resourceDbCustomizeDiff,
+		Timeouts:      defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
+			"query_settings": querySettingsSchema,
 			"cluster": {
 				Description: "Cluster name, not mandatory but should be provided if creating a db in a clustered server",
 				Type:        schema.TypeString,
@@ -31,15 +39,36 @@ func ResourceDb() *schema.Resource {
 				Default:     "",
 			},
 			"name": {
-				Description: "Database name",
+				Description: "Database name. Changing it renames Atomic databases in place, other engines force a new database",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
 			"engine": {
-				Description: "Database engine",
-				Type:        schema.TypeString,
-				Computed:    true,
+				Description:  "Database engine, the server default one (Atomic) when not defined",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(databaseEngines, false),
+			},
+			"engine_params": {
+				Description: "Engine params in case the engine type requires them, e.g. the connection of MySQL or PostgreSQL databases. They are not read back from the server",
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Sensitive:   true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"settings": {
+				Description: "Database settings, supported by engines like MaterializedPostgreSQL",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 			"data_path": {
 				Description: "Database internal path",
@@ -56,11 +85,16 @@ func ResourceDb() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			"deletion_protection": {
+				Description: "Prevents the database from being destroyed",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 			"comment": {
 				Description: "Comment about the database",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     "",
 			},
 		},
@@ -136,7 +170,13 @@ func resourceDbRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 		})
 	}
 
-	// not set - comment
+	err = d.Set("comment", comment)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to set comment for db %q", name),
+		})
+	}
 
 	err = d.Set("cluster", cluster)
 	if err != nil {
@@ -155,31 +195,88 @@ func resourceDbRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 
 func resourceDbCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 	var diags diag.Diagnostics
 
-	cluster, _ := d.Get("cluster").(string)
-	clusterStatement := common.GetClusterStatement(cluster)
-	databaseName := d.Get("name").(string)
-	comment := d.Get("comment").(string)
-	createStatement := common.GetCreateStatement("database")
+	database := models.DatabaseResource{
+		Cluster:      d.Get("cluster").(string),
+		Name:         d.Get("name").(string),
+		Engine:       d.Get("engine").(string),
+		EngineParams: common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{})),
+		Settings:     common.MapInterfaceToMapOfString(d.Get("settings").(map[string]interface{})),
+		Comment:      d.Get("comment").(string),
+	}
 
-	query := fmt.Sprintf("%s %v %v COMMENT '%v'", createStatement, databaseName, clusterStatement, comment)
-	err := c.Conn.Exec(ctx, query)
+	err := c.CreateDatabase(ctx, database)
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 
+	d.SetId(database.Cluster + ":" + database.Name)
+
+	return diags
+}
+
+func resourceDbUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	var diags diag.Diagnostics
+
+	cluster, _ := d.Get("cluster").(string)
+	databaseName := d.Get("name").(string)
+
+	if d.HasChange("name") {
+		oldName, _ := d.GetChange("name")
+		err := c.RenameDatabase(ctx, cluster, oldName.(string), databaseName)
+		if err != nil {
+			return sdk.Diagnostics(err)
+		}
+	}
 	d.SetId(cluster + ":" + databaseName)
 
+	if d.HasChange("comment") {
+		err := c.UpdateDatabaseComment(ctx, cluster, databaseName, d.Get("comment").(string))
+		if err != nil {
+			return sdk.Diagnostics(err)
+		}
+	}
+
 	return diags
 }
 
+func resourceDbCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChange("name") {
+		if engine, _ := d.GetChange("engine"); engine.(string) != "Atomic" {
+			return d.ForceNew("name")
+		}
+		return nil
+	}
+	// a database detached permanently keeps its name, so it can't be created again under the same one
+	if meta.(*sdk.Client).DropPolicy.Mode == sdk.DropModeDetach && d.HasChanges("cluster", "engine", "engine_params", "settings") {
+		return fmt.Errorf("database %s can't be replaced with drop_mode detach, it would be detached permanently under the same name", d.Get("name").(string))
+	}
+	return nil
+}
+
 func resourceDbDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 	var diags diag.Diagnostics
 
 	databaseName := d.Get("name").(string)
 
+	if d.Get("deletion_protection").(bool) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Database %v is protected against deletion", databaseName),
+			Detail:   "Set deletion_protection to false and apply it before destroying the database.",
+		})
+		return diags
+	}
+
 	if databaseName == "" {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -208,13 +305,10 @@ func resourceDbDelete(ctx context.Context, d *schema.ResourceData, meta any) dia
 	}
 
 	cluster, _ := d.Get("cluster").(string)
-	clusterStatement := common.GetClusterStatement(cluster)
-
-	query := fmt.Sprintf("DROP DATABASE %v %v SYNC", databaseName, clusterStatement)
 
-	err = c.Conn.Exec(ctx, query)
+	err = c.DeleteDatabase(ctx, cluster, databaseName)
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 	d.SetId("")
 	return diags
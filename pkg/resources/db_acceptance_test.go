@@ -27,7 +27,7 @@ func TestAccResourceDb(t *testing.T) {
 						"clickhouse_db.new_db", "comment", regexp.MustCompile("^"+testResourceDBDatabaseComment)),
 				),
 			},
-			// RECREATE WITH A DIFFERENT NAME
+			// RENAME THE DATABASE IN PLACE
 			{
 				Config: dbConfig(testResourceDBDatabaseName2, testResourceDBDatabaseComment),
 				Check: resource.ComposeTestCheckFunc(
@@ -37,7 +37,7 @@ func TestAccResourceDb(t *testing.T) {
 						"clickhouse_db.new_db", "comment", regexp.MustCompile("^"+testResourceDBDatabaseComment)),
 				),
 			},
-			// RECREATE WITH A DIFFERENT COMMENT
+			// UPDATE THE COMMENT IN PLACE
 			{
 				Config: dbConfig(testResourceDBDatabaseName2, testResourceDBDatabaseComment2),
 				Check: resource.ComposeTestCheckFunc(
@@ -51,6 +51,27 @@ func TestAccResourceDb(t *testing.T) {
 	})
 }
 
+func TestAccResourceDbEngine(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "clickhouse_db" "lazy_db" {
+		name = "lazy_database"
+		engine = "Lazy"
+		engine_params = ["3600"]
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_db.lazy_db", "engine", "Lazy"),
+					resource.TestCheckResourceAttr("clickhouse_db.lazy_db", "engine_params.0", "3600"),
+				),
+			},
+		},
+	})
+}
+
 func TestGetCreateStatementForDatabase(t *testing.T) {
 	testCases := []testutils.TestCase{
 		{
@@ -0,0 +1,407 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var dictionaryLayouts = []string{
+	"flat", "hashed", "sparse_hashed", "hashed_array", "complex_key_hashed", "complex_key_sparse_hashed",
+	"complex_key_hashed_array", "range_hashed", "complex_key_range_hashed", "ip_trie", "cache",
+	"complex_key_cache", "ssd_cache", "complex_key_ssd_cache", "direct", "complex_key_direct",
+}
+
+func ResourceDictionary() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage dictionaries",
+
+		CreateContext: resourceDictionaryCreate,
+		ReadContext:   resourceDictionaryRead,
+		UpdateContext: resourceDictionaryUpdate,
+		DeleteContext: resourceDictionaryDelete,
+		Timeouts:      defaultTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"database": {
+				Description: "DB Name where the dictionary will bellow",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description: "Dictionary Name",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"query_settings": querySettingsSchema,
+			"cluster": {
+				Description: "Cluster Name",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Computed:    true,
+			},
+			"comment": {
+				Description: "Dictionary comment",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"attribute": {
+				Description: "Dictionary attributes, including the ones of the primary key",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Attribute name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"type": {
+							Description: "Attribute type",
+							Type:        schema.TypeString,
+							Required:    true,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return models.ColumnTypesEqual(old, new)
+							},
+						},
+						"default": {
+							Description: "Default value for the keys not found in the source, as a literal like `'unknown'`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"expression": {
+							Description: "Expression computing the attribute from the source columns",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"hierarchical": {
+							Description: "The attribute holds the parent key of a hierarchy",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						"injective": {
+							Description: "The mapping from the key to the attribute is injective, which allows GROUP BY optimizations",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
+			"primary_key": {
+				Description: "Attributes of the dictionary key, several ones require a `complex_key_*` layout",
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"source": {
+				Description: "Source the dictionary data is loaded from. It's not read back from the server",
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description: "Source type, like `CLICKHOUSE`, `HTTP`, `POSTGRESQL`, `MYSQL` or `FILE`",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"parameters": {
+							Description: "Source parameters, like `table` or `url`. They may hold credentials. The values are quoted, except the numeric `port`, `secure`, `db_index`, `pool_size` and `priority`",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Sensitive:   true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"layout": {
+				Description: "How the dictionary is stored in memory. It's not read back from the server",
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description:  "Layout type, like `hashed`, `complex_key_hashed`, `range_hashed`, `ip_trie` or `cache`",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(dictionaryLayouts, false),
+						},
+						"parameters": {
+							Description: "Layout parameters, like `size_in_cells` for cache layouts",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"lifetime_min": {
+				Description: "Minimum time in seconds before the dictionary is reloaded",
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+			},
+			"lifetime_max": {
+				Description: "Maximum time in seconds before the dictionary is reloaded, 0 disables the periodic reloads",
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+			},
+			"range": {
+				Description: "For range_hashed layouts - attributes holding the validity range of each value",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min": {
+							Description: "Attribute holding the start of the range",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"max": {
+							Description: "Attribute holding the end of the range",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+					},
+				},
+			},
+			"reload_trigger": {
+				Description: "Arbitrary value, changing it reloads the dictionary data with `SYSTEM RELOAD DICTIONARY`",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"status": {
+				Description: "Loading status of the dictionary",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"last_exception": {
+				Description: "Error of the last load of the dictionary, if any",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceDictionaryRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	database := d.Get("database").(string)
+	name := d.Get("name").(string)
+
+	chDictionary, err := c.GetDictionary(ctx, database, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse dictionary: %v", err))
+	}
+	if chDictionary == nil {
+		d.SetId("")
+		return nil
+	}
+	dictionary := chDictionary.ToResource()
+
+	if err := d.Set("database", dictionary.Database); err != nil {
+		return diag.FromErr(fmt.Errorf("setting database: %v", err))
+	}
+	if err := d.Set("name", dictionary.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("comment", dictionary.Comment); err != nil {
+		return diag.FromErr(fmt.Errorf("setting comment: %v", err))
+	}
+	if err := d.Set("attribute", getDictionaryAttributeDefinitions(dictionary.Attributes, d)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting attribute: %v", err))
+	}
+	if err := d.Set("primary_key", dictionary.PrimaryKey); err != nil {
+		return diag.FromErr(fmt.Errorf("setting primary_key: %v", err))
+	}
+	if err := d.Set("lifetime_min", dictionary.LifetimeMin); err != nil {
+		return diag.FromErr(fmt.Errorf("setting lifetime_min: %v", err))
+	}
+	if err := d.Set("lifetime_max", dictionary.LifetimeMax); err != nil {
+		return diag.FromErr(fmt.Errorf("setting lifetime_max: %v", err))
+	}
+	if err := d.Set("status", chDictionary.Status); err != nil {
+		return diag.FromErr(fmt.Errorf("setting status: %v", err))
+	}
+	if err := d.Set("last_exception", chDictionary.LastException); err != nil {
+		return diag.FromErr(fmt.Errorf("setting last_exception: %v", err))
+	}
+
+	d.SetId(d.Get("cluster").(string) + ":" + database + ":" + name)
+
+	return nil
+}
+
+func resourceDictionaryCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	dictionary := getDictionaryResource(d)
+
+	diags := dictionary.Validate()
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := c.CreateDictionary(ctx, dictionary); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	d.SetId(dictionary.Cluster + ":" + dictionary.Database + ":" + dictionary.Name)
+
+	return diags
+}
+
+// any change of the definition is applied with CREATE OR REPLACE DICTIONARY, which reloads the data
+func resourceDictionaryUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	dictionary := getDictionaryResource(d)
+
+	diags := dictionary.Validate()
+	if diags.HasError() {
+		return diags
+	}
+
+	if d.HasChangesExcept("reload_trigger", "query_settings") {
+		if err := c.ReplaceDictionary(ctx, dictionary); err != nil {
+			return sdk.Diagnostics(err)
+		}
+	} else if d.HasChange("reload_trigger") {
+		if err := c.ReloadDictionary(ctx, dictionary); err != nil {
+			return sdk.Diagnostics(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceDictionaryDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+
+	if err := c.DeleteDictionary(ctx, getDictionaryResource(d)); err != nil {
+		return sdk.Diagnostics(err)
+	}
+	return nil
+}
+
+func getDictionaryResource(d *schema.ResourceData) models.DictionaryResource {
+	dictionary := models.DictionaryResource{
+		Database:    d.Get("database").(string),
+		Name:        d.Get("name").(string),
+		Cluster:     d.Get("cluster").(string),
+		PrimaryKey:  common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{})),
+		LifetimeMin: d.Get("lifetime_min").(int),
+		LifetimeMax: d.Get("lifetime_max").(int),
+		Comment:     d.Get("comment").(string),
+	}
+
+	for _, attribute := range d.Get("attribute").([]interface{}) {
+		attributeMap := attribute.(map[string]interface{})
+		dictionary.Attributes = append(dictionary.Attributes, models.DictionaryAttribute{
+			Name:         attributeMap["name"].(string),
+			Type:         attributeMap["type"].(string),
+			Default:      attributeMap["default"].(string),
+			Expression:   attributeMap["expression"].(string),
+			Hierarchical: attributeMap["hierarchical"].(bool),
+			Injective:    attributeMap["injective"].(bool),
+		})
+	}
+	for _, source := range d.Get("source").([]interface{}) {
+		sourceMap := source.(map[string]interface{})
+		dictionary.Source = models.DictionarySource{
+			Type:       sourceMap["type"].(string),
+			Parameters: common.MapInterfaceToMapOfString(sourceMap["parameters"].(map[string]interface{})),
+		}
+	}
+	for _, layout := range d.Get("layout").([]interface{}) {
+		layoutMap := layout.(map[string]interface{})
+		dictionary.Layout = models.DictionaryLayout{
+			Type:       layoutMap["type"].(string),
+			Parameters: common.MapInterfaceToMapOfString(layoutMap["parameters"].(map[string]interface{})),
+		}
+	}
+	for _, dictionaryRange := range d.Get("range").([]interface{}) {
+		rangeMap := dictionaryRange.(map[string]interface{})
+		dictionary.Range = &models.DictionaryRange{
+			Min: rangeMap["min"].(string),
+			Max: rangeMap["max"].(string),
+		}
+	}
+
+	return dictionary
+}
+
+// system.dictionaries only holds the name and type of the attributes, their other properties
+// are kept from the state. The range attributes aren't listed either.
+func getDictionaryAttributeDefinitions(attributes []models.DictionaryAttribute, d *schema.ResourceData) []map[string]interface{} {
+	stateAttributes := make(map[string]map[string]interface{})
+	var stateOrder []string
+	for _, attribute := range d.Get("attribute").([]interface{}) {
+		attributeMap := attribute.(map[string]interface{})
+		stateAttributes[attributeMap["name"].(string)] = attributeMap
+		stateOrder = append(stateOrder, attributeMap["name"].(string))
+	}
+
+	readAttributes := make(map[string]models.DictionaryAttribute)
+	for _, attribute := range attributes {
+		readAttributes[attribute.Name] = attribute
+	}
+
+	var definitions []map[string]interface{}
+	// the attributes are kept in the order of the state when they still exist
+	for _, name := range stateOrder {
+		attribute, ok := readAttributes[name]
+		if !ok {
+			if isDictionaryRangeAttribute(name, d) {
+				definitions = append(definitions, stateAttributes[name])
+			}
+			continue
+		}
+		definition := stateAttributes[name]
+		definition["type"] = attribute.Type
+		definitions = append(definitions, definition)
+		delete(readAttributes, name)
+	}
+	for _, attribute := range attributes {
+		if _, ok := readAttributes[attribute.Name]; !ok {
+			continue
+		}
+		definitions = append(definitions, map[string]interface{}{
+			"name": attribute.Name,
+			"type": attribute.Type,
+		})
+	}
+	return definitions
+}
+
+func isDictionaryRangeAttribute(name string, d *schema.ResourceData) bool {
+	for _, dictionaryRange := range d.Get("range").([]interface{}) {
+		rangeMap := dictionaryRange.(map[string]interface{})
+		if rangeMap["min"] == name || rangeMap["max"] == name {
+			return true
+		}
+	}
+	return false
+}
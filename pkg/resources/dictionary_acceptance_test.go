@@ -0,0 +1,91 @@
+package resources_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceDictionary(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: dictionaryConfig(600, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_dictionary.countries", "primary_key.0", "id"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.countries", "attribute.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.countries", "attribute.1.type", "String"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.countries", "lifetime_max", "600"),
+				),
+			},
+			// REPLACE THE DEFINITION IN PLACE
+			{
+				Config: dictionaryConfig(1200, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_dictionary.countries", "lifetime_max", "1200"),
+				),
+			},
+			// RELOAD THE DICTIONARY
+			{
+				Config: dictionaryConfig(1200, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_dictionary.countries", "status", "LOADED"),
+				),
+			},
+		},
+	})
+}
+
+func dictionaryConfig(lifetimeMax int, reloadTrigger string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "dictionary_db" {
+		name = "dictionary_database"
+	}
+
+	resource "clickhouse_table" "countries" {
+		database = clickhouse_db.dictionary_db.name
+		name = "countries"
+		engine = "MergeTree"
+		order_by = ["id"]
+		column {
+			name = "id"
+			type = "UInt64"
+		}
+		column {
+			name = "name"
+			type = "String"
+		}
+	}
+
+	resource "clickhouse_dictionary" "countries" {
+		database = clickhouse_db.dictionary_db.name
+		name = "countries_dict"
+		primary_key = ["id"]
+		attribute {
+			name = "id"
+			type = "UInt64"
+		}
+		attribute {
+			name = "name"
+			type = "String"
+			default = "'unknown'"
+		}
+		source {
+			type = "CLICKHOUSE"
+			parameters = {
+				db = "dictionary_database"
+				table = "countries"
+			}
+		}
+		layout {
+			type = "hashed"
+		}
+		lifetime_max = %d
+		reload_trigger = "%s"
+		depends_on = [clickhouse_table.countries]
+	}`, lifetimeMax, reloadTrigger)
+}
@@ -0,0 +1,137 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceFunction() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage SQL user defined functions",
+
+		CreateContext: resourceFunctionCreate,
+		ReadContext:   resourceFunctionRead,
+		UpdateContext: resourceFunctionUpdate,
+		DeleteContext: resourceFunctionDelete,
+		Timeouts:      defaultTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"query_settings": querySettingsSchema,
+			"name": {
+				Description: "Function name",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"cluster": {
+				Description: "Cluster Name",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Computed:    true,
+			},
+			"arguments": {
+				Description: "Names of the function arguments",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"expression": {
+				Description: "Expression computing the function result from its arguments. Changing it replaces the function with `CREATE OR REPLACE FUNCTION`",
+				Type:        schema.TypeString,
+				Required:    true,
+				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
+					return strings.Join(strings.Fields(oldValue), " ") == strings.Join(strings.Fields(newValue), " ")
+				},
+			},
+		},
+	}
+}
+
+func resourceFunctionRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	name := d.Get("name").(string)
+
+	chFunction, err := c.GetFunction(ctx, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse function: %v", err))
+	}
+	if chFunction == nil {
+		d.SetId("")
+		return nil
+	}
+	function := chFunction.ToResource()
+
+	if err := d.Set("name", function.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("arguments", function.Arguments); err != nil {
+		return diag.FromErr(fmt.Errorf("setting arguments: %v", err))
+	}
+	// the expression is formatted by Clickhouse, so it's only read back when it's not equivalent to the state
+	if !c.QueriesEqual(ctx, "SELECT "+d.Get("expression").(string), "SELECT "+function.Expression) {
+		if err := d.Set("expression", function.Expression); err != nil {
+			return diag.FromErr(fmt.Errorf("setting expression: %v", err))
+		}
+	}
+
+	d.SetId(d.Get("cluster").(string) + ":" + name)
+
+	return nil
+}
+
+func resourceFunctionCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	function := getFunctionResource(d)
+
+	if err := c.CreateFunction(ctx, function); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	d.SetId(function.Cluster + ":" + function.Name)
+
+	return diags
+}
+
+func resourceFunctionUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+
+	if err := c.ReplaceFunction(ctx, getFunctionResource(d)); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	return diags
+}
+
+func resourceFunctionDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+
+	if err := c.DeleteFunction(ctx, getFunctionResource(d)); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	return diags
+}
+
+func getFunctionResource(d *schema.ResourceData) models.FunctionResource {
+	return models.FunctionResource{
+		Name:       d.Get("name").(string),
+		Cluster:    d.Get("cluster").(string),
+		Arguments:  common.MapArrayInterfaceToArrayOfStrings(d.Get("arguments").([]interface{})),
+		Expression: d.Get("expression").(string),
+	}
+}
@@ -0,0 +1,41 @@
+package resources_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: functionConfig("k * x + b"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_function.linear_equation", "arguments.#", "3"),
+					resource.TestCheckResourceAttr("clickhouse_function.linear_equation", "expression", "k * x + b"),
+				),
+			},
+			// REPLACE THE FUNCTION
+			{
+				Config: functionConfig("k * x - b"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_function.linear_equation", "expression", "k * x - b"),
+				),
+			},
+		},
+	})
+}
+
+func functionConfig(expression string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_function" "linear_equation" {
+		name = "test_linear_equation"
+		arguments = ["x", "k", "b"]
+		expression = "%s"
+	}`, expression)
+}
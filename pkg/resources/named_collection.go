@@ -0,0 +1,195 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceNamedCollection() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage named collections, which hold the connection settings and credentials of integration engines and table functions",
+
+		CreateContext: resourceNamedCollectionCreate,
+		ReadContext:   resourceNamedCollectionRead,
+		UpdateContext: resourceNamedCollectionUpdate,
+		DeleteContext: resourceNamedCollectionDelete,
+		Timeouts:      defaultTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"query_settings": querySettingsSchema,
+			"name": {
+				Description: "Named collection name",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"cluster": {
+				Description: "Cluster Name",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Computed:    true,
+			},
+			"param": {
+				Description: "Key/value pairs of the collection. Changes are applied in place with `ALTER NAMED COLLECTION`. Only the keys are read back from the server",
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Description: "Key name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"value": {
+							Description: "Key value",
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+						},
+						"overridable": {
+							Description: "Whether the value can be overridden by the queries using the collection",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceNamedCollectionRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	c := meta.(*sdk.Client)
+	name := d.Get("name").(string)
+
+	chNamedCollection, err := c.GetNamedCollection(ctx, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse named collection: %v", err))
+	}
+	if chNamedCollection == nil {
+		d.SetId("")
+		return nil
+	}
+
+	if err := d.Set("name", chNamedCollection.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("param", getNamedCollectionParamDefinitions(chNamedCollection.Keys, d)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting param: %v", err))
+	}
+
+	d.SetId(d.Get("cluster").(string) + ":" + name)
+
+	return nil
+}
+
+func resourceNamedCollectionCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	collection := getNamedCollectionResource(d)
+
+	if err := c.CreateNamedCollection(ctx, collection); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	d.SetId(collection.Cluster + ":" + collection.Name)
+
+	return diags
+}
+
+func resourceNamedCollectionUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	collection := getNamedCollectionResource(d)
+
+	oldParams, _ := d.GetChange("param")
+	previous := make(map[string]models.NamedCollectionParam)
+	for _, param := range getNamedCollectionParams(oldParams.(*schema.Set)) {
+		previous[param.Key] = param
+	}
+
+	var setParams []models.NamedCollectionParam
+	for _, param := range collection.Params {
+		if previousParam, ok := previous[param.Key]; !ok || previousParam != param {
+			setParams = append(setParams, param)
+		}
+		delete(previous, param.Key)
+	}
+	var deleteKeys []string
+	for key := range previous {
+		deleteKeys = append(deleteKeys, key)
+	}
+	sort.Strings(deleteKeys)
+
+	if err := c.AlterNamedCollection(ctx, collection, setParams, deleteKeys); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	return diags
+}
+
+func resourceNamedCollectionDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+
+	if err := c.DeleteNamedCollection(ctx, getNamedCollectionResource(d)); err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	return diags
+}
+
+func getNamedCollectionResource(d *schema.ResourceData) models.NamedCollectionResource {
+	return models.NamedCollectionResource{
+		Name:    d.Get("name").(string),
+		Cluster: d.Get("cluster").(string),
+		Params:  getNamedCollectionParams(d.Get("param").(*schema.Set)),
+	}
+}
+
+func getNamedCollectionParams(params *schema.Set) []models.NamedCollectionParam {
+	var collectionParams []models.NamedCollectionParam
+	for _, param := range params.List() {
+		paramMap := param.(map[string]interface{})
+		collectionParams = append(collectionParams, models.NamedCollectionParam{
+			Key:         paramMap["key"].(string),
+			Value:       paramMap["value"].(string),
+			Overridable: paramMap["overridable"].(bool),
+		})
+	}
+	return collectionParams
+}
+
+// the values are masked in system.named_collections, so they are kept from the state. Keys added
+// outside of Terraform are read with an empty value to show the drift.
+func getNamedCollectionParamDefinitions(keys []string, d *schema.ResourceData) []map[string]interface{} {
+	stateParams := make(map[string]map[string]interface{})
+	for _, param := range d.Get("param").(*schema.Set).List() {
+		paramMap := param.(map[string]interface{})
+		stateParams[paramMap["key"].(string)] = paramMap
+	}
+
+	definitions := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if param, ok := stateParams[key]; ok {
+			definitions = append(definitions, param)
+			continue
+		}
+		definitions = append(definitions, map[string]interface{}{
+			"key":         key,
+			"value":       "",
+			"overridable": true,
+		})
+	}
+	return definitions
+}
@@ -0,0 +1,57 @@
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceNamedCollection(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "clickhouse_named_collection" "collection" {
+		name = "test_collection"
+		param {
+			key = "host"
+			value = "localhost"
+		}
+		param {
+			key = "password"
+			value = "secret"
+			overridable = false
+		}
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_named_collection.collection", "param.#", "2"),
+				),
+			},
+			// SET AND DELETE KEYS IN PLACE
+			{
+				Config: `
+	resource "clickhouse_named_collection" "collection" {
+		name = "test_collection"
+		param {
+			key = "host"
+			value = "127.0.0.1"
+		}
+		param {
+			key = "port"
+			value = "5432"
+		}
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_named_collection.collection", "param.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("clickhouse_named_collection.collection", "param.*", map[string]string{
+						"key":   "port",
+						"value": "5432",
+					}),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,22 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var querySettingsSchema = &schema.Schema{
+	Description: "Settings applied to the DDL queries of the resource on top of the provider settings, e.g. the `allow_experimental_*` flags required by its definition. They are not part of the definition, changing them has no effect until another change is applied",
+	Type:        schema.TypeMap,
+	Optional:    true,
+	Elem: &schema.Schema{
+		Type: schema.TypeString,
+	},
+}
+
+func withQuerySettings(ctx context.Context, d resourceGetter) context.Context {
+	return sdk.WithQuerySettings(ctx, common.MapInterfaceToMapOfString(d.Get("query_settings").(map[string]interface{})))
+}
@@ -0,0 +1,47 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var checkAllReplicasSchema = &schema.Schema{
+	Description: "Read the definition from all the replicas of the cluster with `clusterAllReplicas()` and report the hosts where it diverges in `divergent_hosts`. Requires `cluster`",
+	Type:        schema.TypeBool,
+	Optional:    true,
+	Default:     false,
+}
+
+var divergentHostsSchema = &schema.Schema{
+	Description: "Hosts of the cluster where the definition is missing or differs from the connected node, only read when `check_all_replicas` is set. A non empty list plans an in place repair running the definition again `ON CLUSTER`",
+	Type:        schema.TypeList,
+	Computed:    true,
+	Elem: &schema.Schema{
+		Type: schema.TypeString,
+	},
+}
+
+func setDivergentHosts(ctx context.Context, d *schema.ResourceData, divergences []models.HostDivergence) error {
+	hosts := make([]string, 0, len(divergences))
+	for _, divergence := range divergences {
+		tflog.Warn(ctx, fmt.Sprintf("%s diverges on host %s", d.Id(), divergence))
+		hosts = append(hosts, divergence.Host)
+	}
+	if err := d.Set("divergent_hosts", hosts); err != nil {
+		return fmt.Errorf("setting divergent_hosts: %v", err)
+	}
+	return nil
+}
+
+// planDivergentHostsRepair plans the repair of the divergent hosts by clearing them, the update
+// then runs the definition again on the cluster
+func planDivergentHostsRepair(d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.Get("check_all_replicas").(bool) || len(d.Get("divergent_hosts").([]interface{})) == 0 {
+		return nil
+	}
+	return d.SetNew("divergent_hosts", []string{})
+}
@@ -18,7 +18,9 @@ func ResourceRole() *schema.Resource {
 		ReadContext:   resourceRoleRead,
 		DeleteContext: resourceRoleDelete,
 		UpdateContext: resourceRoleUpdate,
+		Timeouts:      defaultTimeouts(),
 		Schema: map[string]*schema.Schema{
+			"query_settings": querySettingsSchema,
 			"name": {
 				Description: "Role name",
 				Type:        schema.TypeString,
@@ -45,6 +47,7 @@ func resourceRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	var diags diag.Diagnostics
 
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	planRoleName := d.Get("name").(string)
 	planDatabase := d.Get("database").(string)
@@ -107,6 +110,7 @@ func resourceRoleRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	database := d.Get("database").(string)
 	roleName := d.Get("name").(string)
@@ -131,6 +135,7 @@ func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta any) d
 func resourceRoleDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	roleName := d.Get("name").(string)
 
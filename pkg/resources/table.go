@@ -3,12 +3,16 @@ package resources
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func ResourceTable() *schema.Resource {
@@ -19,12 +23,13 @@ func ResourceTable() *schema.Resource {
 		ReadContext:   resourceTableRead,
 		DeleteContext: resourceTableDelete,
 		UpdateContext: resourceTableUpdate,
+		CustomizeDiff: resourceTableCustomizeDiff,
+		Timeouts:      tableTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"database": {
-				Description: "DB Name where the table will bellow",
+				Description: "DB Name where the table will bellow. Changing it moves the table to the new database",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
 			"comment": {
 				Description: "Database comment, it will be codified in a json along with come metadata information (like cluster name in case of clustering)",
@@ -32,82 +37,280 @@ func ResourceTable() *schema.Resource {
 				Optional:    true,
 			},
 			"name": {
-				Description: "Table Name",
+				Description: "Table Name. Changing it renames the table in place",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
+			"query_settings": querySettingsSchema,
 			"cluster": {
 				Description: "Cluster Name, it is required for Replicated or Distributed tables and forbidden in other case",
 				Type:        schema.TypeString,
 				Optional:    true,
 				ForceNew:    true,
 			},
+			"check_all_replicas": checkAllReplicasSchema,
+			"divergent_hosts":    divergentHostsSchema,
 			"engine": {
-				Description: "Table engine type (Supported types so far: Distributed, ReplicatedReplacingMergeTree, ReplacingMergeTree)",
+				Description: "Table engine type. The engine params, clauses and settings of the known engines are validated at plan time. Changing it replaces the table according to `replace_strategy`",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
 			"engine_params": {
-				Description: "Engine params in case the engine type requires them",
-				Type:        schema.TypeList,
-				Optional:    true,
-				ForceNew:    true,
+				Description:   "Engine params in case the engine type requires them. Changing it replaces the table according to `replace_strategy`",
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"distributed", "integration"},
 				Elem: &schema.Schema{
-					Type:     schema.TypeString,
-					ForceNew: true,
+					Type: schema.TypeString,
 				},
 			},
-			"primary_key": {
-				Description: "Columns to use as primary key",
-				Type:        schema.TypeList,
-				Optional:    true,
-				ForceNew:    true,
-				Elem: &schema.Schema{
-					Type:     schema.TypeString,
-					ForceNew: true,
+			"zookeeper_path": {
+				Description:  "ZooKeeper path of Replicated engine tables, it may hold macros like {shard}, {database}, {table} or {uuid}. The server default_replica_path is used when it's not defined. Changing it replaces the table according to `replace_strategy`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"replica_name"},
+			},
+			"replica_name": {
+				Description:  "Replica name of Replicated engine tables, it may hold macros like {replica}. The server default_replica_name is used when it's not defined. Changing it replaces the table according to `replace_strategy`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"zookeeper_path"},
+			},
+			"distributed": {
+				Description:   "Tables a `Distributed` engine table reads from and writes to, in place of `engine_params`. When no column is defined, the columns are copied from the remote table with `AS`, and the table is replaced when the remote table columns change. Distributed settings are set with `settings`. Changing it replaces the table according to `replace_strategy`",
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"engine_params", "integration"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cluster": {
+							Description: "Cluster of the remote tables",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"database": {
+							Description: "Database of the remote tables",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"table": {
+							Description: "Name of the remote tables, usually the local tables of each shard",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"sharding_key": {
+							Description: "Sharding key expression used to pick the shard of the inserted rows",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"policy_name": {
+							Description:  "Storage policy of the temporary files used for the background inserts, it requires `sharding_key`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							RequiredWith: []string{"distributed.0.sharding_key"},
+						},
+					},
 				},
 			},
-			"order_by": {
-				Description: "Order by columns to use as sorting key",
+			"kafka": {
+				Description: "Settings of a `Kafka` engine table, merged with `settings`. The broker list, the consumers, the error handling and the credentials are changed in place with `ALTER TABLE ... MODIFY SETTING`, changing the topics, the consumer group, the format or the schema replaces the table according to `replace_strategy`",
 				Type:        schema.TypeList,
 				Optional:    true,
-				ForceNew:    true,
-				Elem: &schema.Schema{
-					Type:     schema.TypeString,
-					ForceNew: true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"broker_list": {
+							Description: "Comma separated list of brokers",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"topic_list": {
+							Description: "Topics to consume",
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"group_name": {
+							Description: "Consumer group name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"format": {
+							Description: "Message format, like JSONEachRow",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"num_consumers": {
+							Description:  "Number of consumers per table",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"schema": {
+							Description: "Schema of the formats requiring one, like Protobuf or CapnProto",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"handle_error_mode": {
+							Description:  "How the messages that can't be parsed are handled: `default`, `stream` or `dead_letter_queue`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"default", "stream", "dead_letter_queue"}, false),
+						},
+						"security_protocol": {
+							Description:  "Protocol used to communicate with the brokers: `plaintext`, `ssl`, `sasl_plaintext` or `sasl_ssl`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"plaintext", "ssl", "sasl_plaintext", "sasl_ssl"}, true),
+						},
+						"sasl_mechanism": {
+							Description: "SASL mechanism, like PLAIN or SCRAM-SHA-512",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"sasl_username": {
+							Description: "SASL username. It is hidden by Clickhouse, so it is kept from the state",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"sasl_password": {
+							Description: "SASL password. It is hidden by Clickhouse, so it is kept from the state",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+						},
+					},
 				},
 			},
-			"partition_by": {
-				Description: "Partition Key to split data",
+			"integration": {
+				Description: "Arguments of a `S3`, `S3Queue`, `AzureBlobStorage`, `URL` or `File` engine table, in place of `engine_params`. The credentials are hidden by Clickhouse, so they are kept from the state. Changing it replaces the table according to `replace_strategy`",
 				Type:        schema.TypeList,
 				Optional:    true,
-				ForceNew:    true,
+				MaxItems:    1,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						"by": {
-							Description: "Column to use as part of the partition key",
+						"named_collection": {
+							Description: "Named collection holding the connection arguments, the other arguments override its values",
 							Type:        schema.TypeString,
-							Required:    true,
-							ForceNew:    true,
+							Optional:    true,
 						},
-						"partition_function": {
-							Description: "Partition function, could be empty or one of following: toYYYYMM, toYYYYMMDD or toYYYYMMDDhhmmss",
+						"endpoint": {
+							Description: "Connection string or storage account URL of `AzureBlobStorage` tables",
 							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     nil,
-							ForceNew:    true,
+							Sensitive:   true,
 						},
-						"mod": {
-							Description: "Modulo to apply to the partition function",
+						"container": {
+							Description: "Container of `AzureBlobStorage` tables",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"path": {
+							Description: "URL of `S3`, `S3Queue` and `URL` tables, blob path of `AzureBlobStorage` tables. It may hold globs to read many files, and the `{_partition_id}` wildcard, required along with `partition_by`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"format": {
+							Description: "Data format, like Parquet or CSVWithNames",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"compression": {
+							Description: "Compression method, detected from the path extension when empty",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"access_key_id": {
+							Description:  "Access key id of `S3` and `S3Queue` tables, account name of `AzureBlobStorage` tables",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							RequiredWith: []string{"integration.0.secret_access_key"},
+						},
+						"secret_access_key": {
+							Description:  "Secret access key of `S3` and `S3Queue` tables, account key of `AzureBlobStorage` tables",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							RequiredWith: []string{"integration.0.access_key_id"},
+						},
+						"mode": {
+							Description:  "How `S3Queue` tables track the processed files: `ordered` or `unordered`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"ordered", "unordered"}, false),
+						},
+						"after_processing": {
+							Description:  "What `S3Queue` tables do with the processed files: `keep` or `delete`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"keep", "delete"}, false),
+						},
+						"keeper_path": {
+							Description: "Keeper path where `S3Queue` tables store the processed files",
 							Type:        schema.TypeString,
 							Optional:    true,
-							ForceNew:    true,
 						},
 					},
 				},
 			},
+			"inherited_columns": {
+				Description: "Columns copied from the remote table of a `distributed` table without columns",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Column Name",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type": {
+							Description: "Column Type",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"primary_key": {
+				Description: "Columns to use as primary key. Changing it replaces the table according to `replace_strategy`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"order_by": {
+				Description: "Order by columns to use as sorting key. Appending columns added in the same change modifies the sorting key in place, any other change replaces the table according to `replace_strategy`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"sample_by": {
+				Description: "Sampling expression, it must be part of the primary key",
+				Type:        schema.TypeString,
+				Optional:    true,
+				// Clickhouse formats the sampling key again when reading it
+				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
+					return common.NormalizeExpression(oldValue) == common.NormalizeExpression(newValue)
+				},
+			},
+			"partition_by": {
+				Description: "Partition Key to split data. Changing it replaces the table according to `replace_strategy`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        partitionByElem(),
+			},
 			"column": {
 				Description: "Column",
 				Type:        schema.TypeList,
@@ -120,9 +323,12 @@ func ResourceTable() *schema.Resource {
 							Required:    true,
 						},
 						"type": {
-							Description: "Column Type",
+							Description: "Column Type. Composite types (Nested, Tuple, Map, JSON, Variant, Dynamic) are compared structurally",
 							Type:        schema.TypeString,
 							Required:    true,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return models.ColumnTypesEqual(old, new)
+							},
 						},
 						"comment": {
 							Description: "Column Comment",
@@ -152,10 +358,9 @@ func ResourceTable() *schema.Resource {
 				},
 			},
 			"settings": {
-				Description: "Table settings",
+				Description: "Table settings. Changing it replaces the table according to `replace_strategy`",
 				Type:        schema.TypeMap,
 				Optional:    true,
-				ForceNew:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
@@ -168,36 +373,52 @@ func ResourceTable() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"deletion_protection": {
+				Description: "Prevents the table from being destroyed, including replacements with the `drop_and_create` strategy",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"replace_strategy": {
+				Description:  "How the table is replaced when an attribute that can't be altered in place changes: `drop_and_create` drops the table and creates it again losing its data, `copy_and_exchange` creates a shadow table, copies the data into it with `INSERT ... SELECT` and swaps both tables with `EXCHANGE TABLES` (requires an Atomic database). When a cluster is set, the data is copied from the connected node only, so the new definition should be Replicated, with a ZooKeeper path holding the {uuid} macro (as the server default one) so the shadow table gets its own path.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "drop_and_create",
+				ValidateFunc: validation.StringInSlice([]string{"drop_and_create", "copy_and_exchange"}, false),
+			},
+			"copy_column_mapping": {
+				Description: "For `copy_and_exchange` replacements, expressions over the previous table used to fill the columns of the new definition, indexed by column name. Columns not mapped are copied by name when they exist in the previous table",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"index": {
-				Description: "Index",
+				Description: "Index. Changing it replaces the table according to `replace_strategy`",
 				Type:        schema.TypeList,
 				Optional:    true,
-				ForceNew:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
 							Description: "Index Name",
 							Type:        schema.TypeString,
 							Required:    true,
-							ForceNew:    true,
 						},
 						"expression": {
 							Description: "Index Expression",
 							Type:        schema.TypeString,
 							Required:    true,
-							ForceNew:    true,
 						},
 						"type": {
 							Description: "Index Type",
 							Type:        schema.TypeString,
 							Required:    true,
-							ForceNew:    true,
 						},
 						"granularity": {
 							Description: "Index Granularity",
 							Type:        schema.TypeInt,
 							Optional:    true,
-							ForceNew:    true,
 						},
 					},
 				},
@@ -206,6 +427,29 @@ func ResourceTable() *schema.Resource {
 	}
 }
 
+func partitionByElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"by": {
+				Description: "Column to use as part of the partition key",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"partition_function": {
+				Description: "Partition function, could be empty or one of following: toYYYYMM, toYYYYMMDD or toYYYYMMDDhhmmss",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     nil,
+			},
+			"mod": {
+				Description: "Modulo to apply to the partition function",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+		},
+	}
+}
+
 func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 
@@ -227,6 +471,8 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("transforming Clickhouse table to resource: %v", err))
 	}
+	tableResource.CollapseNestedColumns(getNestedColumnNames(d.Get("column").([]interface{})))
+	tableResource.KeepObjectColumnTypes(getColumnTypes(d.Get("column").([]interface{})))
 
 	if err := d.Set("database", tableResource.Database); err != nil {
 		return diag.FromErr(fmt.Errorf("setting database: %v", err))
@@ -247,14 +493,29 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 	if err := d.Set("engine", tableResource.Engine); err != nil {
 		return diag.FromErr(fmt.Errorf("setting engine: %v", err))
 	}
-	if tableResource.EngineParams != nil {
+	if len(d.Get("distributed").([]interface{})) > 0 {
+		if err := d.Set("distributed", getDistributedDefinitions(models.GetDistributedResource(tableResource.EngineParams))); err != nil {
+			return diag.FromErr(fmt.Errorf("setting distributed: %v", err))
+		}
+	} else if stateIntegration := getIntegrationResource(d.Get("integration").([]interface{})); stateIntegration != nil {
+		// the engine params are not read back, they may hold the credentials
+		integration := models.GetIntegrationResource(tableResource.Engine, tableResource.EngineParams, tableResource.Settings, stateIntegration)
+		if err := d.Set("integration", getIntegrationDefinitions(integration)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting integration: %v", err))
+		}
+	} else if tableResource.ZooKeeperPath != "" {
+		if diags := setReplicationParams(ctx, d, c, chTable, tableResource); diags.HasError() {
+			return diags
+		}
+	} else if tableResource.EngineParams != nil {
 		if err := d.Set("engine_params", tableResource.EngineParams); err != nil {
 			return diag.FromErr(fmt.Errorf("setting engine_params: %v", err))
 		}
 	}
-	if tableResource.PrimaryKey != nil {
-		if err := d.Set("primary_key", tableResource.OrderBy); err != nil {
-			return diag.FromErr(fmt.Errorf("setting order_by: %v", err))
+	// the primary key defaults to the sorting key, so it's only read back when defined explicitly
+	if len(d.Get("primary_key").([]interface{})) > 0 {
+		if err := d.Set("primary_key", tableResource.PrimaryKey); err != nil {
+			return diag.FromErr(fmt.Errorf("setting primary_key: %v", err))
 		}
 	}
 	if tableResource.OrderBy != nil {
@@ -262,9 +523,33 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 			return diag.FromErr(fmt.Errorf("setting order_by: %v", err))
 		}
 	}
+	if err := d.Set("sample_by", tableResource.SampleBy); err != nil {
+		return diag.FromErr(fmt.Errorf("setting sample_by: %v", err))
+	}
+	if stateKafka := getKafkaResource(d.Get("kafka").([]interface{})); stateKafka != nil {
+		// the sensitive settings are hidden by Clickhouse, they are kept from the state
+		settings := make(map[string]string, len(tableResource.Settings))
+		for key, value := range tableResource.Settings {
+			settings[key] = value
+		}
+		stateSettings := stateKafka.Settings()
+		for _, key := range models.KafkaSensitiveSettings {
+			settings[key] = stateSettings[key]
+		}
+		kafka := models.GetKafkaResource(settings)
+		if err := d.Set("kafka", getKafkaDefinitions(kafka)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting kafka: %v", err))
+		}
+	}
 	// not set - partition_by
-	if err := d.Set("column", c.GetColumnDefintions(tableResource.Columns)); err != nil {
-		return diag.FromErr(fmt.Errorf("setting column: %v", err))
+	if isInheritingColumns(d) {
+		if err := d.Set("inherited_columns", getInheritedColumnDefinitions(tableResource.Columns)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting inherited_columns: %v", err))
+		}
+	} else {
+		if err := d.Set("column", c.GetColumnDefintions(tableResource.Columns)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting column: %v", err))
+		}
 	}
 	if tableResource.Indexes != nil {
 		if err := d.Set("index", c.GetIndexDefintions(tableResource.Indexes)); err != nil {
@@ -272,6 +557,15 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 		}
 	}
 	// not set - settings
+	if d.Get("check_all_replicas").(bool) {
+		divergences, err := c.GetTableDivergences(ctx, tableResource.Cluster, database, tableName)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("reading table on all replicas: %v", err))
+		}
+		if err := setDivergentHosts(ctx, d, divergences); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	d.SetId(tableResource.Cluster + ":" + database + ":" + tableName)
 
@@ -282,6 +576,26 @@ func resourceTableCreate(ctx context.Context, d *schema.ResourceData, meta any)
 	var diags diag.Diagnostics
 
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	tableResource := getTableResource(d)
+
+	tableResource.Validate(diags)
+	if diags.HasError() {
+		return diags
+	}
+
+	err := c.CreateTable(ctx, tableResource)
+
+	if err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	d.SetId(tableResource.Cluster + ":" + tableResource.Database + ":" + tableResource.Name)
+
+	return diags
+}
+
+func getTableResource(d *schema.ResourceData) models.TableResource {
 	tableResource := models.TableResource{}
 
 	tableResource.Cluster = d.Get("cluster").(string)
@@ -294,29 +608,289 @@ func resourceTableCreate(ctx context.Context, d *schema.ResourceData, meta any)
 	tableResource.EngineParams = common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{}))
 	tableResource.PrimaryKey = common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{}))
 	tableResource.OrderBy = common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{}))
+	tableResource.SampleBy = d.Get("sample_by").(string)
 	tableResource.SetPartitionBy(d.Get("partition_by").([]interface{}))
 	tableResource.Settings = common.MapInterfaceToMapOfString(d.Get("settings").(map[string]interface{}))
 	tableResource.TTL = common.MapInterfaceToMapOfString(d.Get("ttl").(map[string]interface{}))
+	// the server values would leave the ZooKeeper path of the shadow tables with the macros expanded
+	tableResource.ZooKeeperPath = getConfiguredString(d, "zookeeper_path")
+	tableResource.ReplicaName = getConfiguredString(d, "replica_name")
+	if replicationParams := tableResource.ReplicationParams(); replicationParams != nil {
+		tableResource.EngineParams = append(replicationParams, tableResource.EngineParams...)
+	}
+	if distributed := getDistributedResource(d.Get("distributed").([]interface{})); distributed != nil {
+		tableResource.Distributed = distributed
+		tableResource.EngineParams = distributed.EngineParams()
+		tableResource.AsTable = distributed.Database + "." + distributed.Table
+	}
+	if kafka := getKafkaResource(d.Get("kafka").([]interface{})); kafka != nil {
+		tableResource.Kafka = kafka
+		tableResource.Settings = mergeSettings(tableResource.Settings, kafka.Settings())
+	}
+	if integration := getIntegrationResource(d.Get("integration").([]interface{})); integration != nil {
+		tableResource.Integration = integration
+		tableResource.EngineParams = integration.EngineParams(tableResource.Engine)
+		tableResource.Settings = mergeSettings(tableResource.Settings, integration.Settings())
+	}
 
-	tableResource.Validate(diags)
-	if diags.HasError() {
-		return diags
+	return tableResource
+}
+
+func mergeSettings(settings map[string]string, engineSettings map[string]string) map[string]string {
+	merged := make(map[string]string, len(settings)+len(engineSettings))
+	for key, value := range settings {
+		merged[key] = value
 	}
+	for key, value := range engineSettings {
+		merged[key] = value
+	}
+	return merged
+}
 
-	err := c.CreateTable(ctx, tableResource)
+func getIntegrationResource(integration []interface{}) *models.IntegrationResource {
+	if len(integration) == 0 || integration[0] == nil {
+		return nil
+	}
+	integrationMap := integration[0].(map[string]interface{})
+	return &models.IntegrationResource{
+		NamedCollection: integrationMap["named_collection"].(string),
+		Endpoint:        integrationMap["endpoint"].(string),
+		Container:       integrationMap["container"].(string),
+		Path:            integrationMap["path"].(string),
+		Format:          integrationMap["format"].(string),
+		Compression:     integrationMap["compression"].(string),
+		AccessKeyID:     integrationMap["access_key_id"].(string),
+		SecretAccessKey: integrationMap["secret_access_key"].(string),
+		Mode:            integrationMap["mode"].(string),
+		AfterProcessing: integrationMap["after_processing"].(string),
+		KeeperPath:      integrationMap["keeper_path"].(string),
+	}
+}
+
+func getIntegrationDefinitions(integration *models.IntegrationResource) []map[string]interface{} {
+	return []map[string]interface{}{{
+		"named_collection":  integration.NamedCollection,
+		"endpoint":          integration.Endpoint,
+		"container":         integration.Container,
+		"path":              integration.Path,
+		"format":            integration.Format,
+		"compression":       integration.Compression,
+		"access_key_id":     integration.AccessKeyID,
+		"secret_access_key": integration.SecretAccessKey,
+		"mode":              integration.Mode,
+		"after_processing":  integration.AfterProcessing,
+		"keeper_path":       integration.KeeperPath,
+	}}
+}
 
+func getKafkaResource(kafka []interface{}) *models.KafkaResource {
+	if len(kafka) == 0 || kafka[0] == nil {
+		return nil
+	}
+	kafkaMap := kafka[0].(map[string]interface{})
+	return &models.KafkaResource{
+		BrokerList:       kafkaMap["broker_list"].(string),
+		TopicList:        common.MapArrayInterfaceToArrayOfStrings(kafkaMap["topic_list"].([]interface{})),
+		GroupName:        kafkaMap["group_name"].(string),
+		Format:           kafkaMap["format"].(string),
+		NumConsumers:     kafkaMap["num_consumers"].(int),
+		Schema:           kafkaMap["schema"].(string),
+		HandleErrorMode:  kafkaMap["handle_error_mode"].(string),
+		SecurityProtocol: kafkaMap["security_protocol"].(string),
+		SASLMechanism:    kafkaMap["sasl_mechanism"].(string),
+		SASLUsername:     kafkaMap["sasl_username"].(string),
+		SASLPassword:     kafkaMap["sasl_password"].(string),
+	}
+}
+
+func getKafkaDefinitions(kafka *models.KafkaResource) []map[string]interface{} {
+	return []map[string]interface{}{{
+		"broker_list":       kafka.BrokerList,
+		"topic_list":        kafka.TopicList,
+		"group_name":        kafka.GroupName,
+		"format":            kafka.Format,
+		"num_consumers":     kafka.NumConsumers,
+		"schema":            kafka.Schema,
+		"handle_error_mode": kafka.HandleErrorMode,
+		"security_protocol": kafka.SecurityProtocol,
+		"sasl_mechanism":    kafka.SASLMechanism,
+		"sasl_username":     kafka.SASLUsername,
+		"sasl_password":     kafka.SASLPassword,
+	}}
+}
+
+// getKafkaSettingsChanges returns the Kafka settings to modify and to reset in place
+func getKafkaSettingsChanges(d resourceChanges) (map[string]string, []string) {
+	oldKafka, newKafka := d.GetChange("kafka")
+	oldSettings := getKafkaResource(oldKafka.([]interface{})).Settings()
+	newSettings := getKafkaResource(newKafka.([]interface{})).Settings()
+
+	modified := make(map[string]string)
+	for key, value := range newSettings {
+		if oldValue, ok := oldSettings[key]; !ok || oldValue != value {
+			modified[key] = value
+		}
+	}
+	var reset []string
+	for key := range oldSettings {
+		if _, ok := newSettings[key]; !ok {
+			reset = append(reset, key)
+		}
+	}
+	sort.Strings(reset)
+	return modified, reset
+}
+
+// isKafkaSettingsChange returns whether the Kafka settings changes can be applied in place
+func isKafkaSettingsChange(d resourceChanges) bool {
+	oldKafka, newKafka := d.GetChange("kafka")
+	if len(oldKafka.([]interface{})) == 0 || len(newKafka.([]interface{})) == 0 {
+		return false
+	}
+	modified, reset := getKafkaSettingsChanges(d)
+	for key := range modified {
+		if !common.Contains(models.KafkaAlterableSettings, key) {
+			return false
+		}
+	}
+	for _, key := range reset {
+		if !common.Contains(models.KafkaAlterableSettings, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// setReplicationParams sets the ZooKeeper path and replica name of Replicated tables. Clickhouse
+// stores them with some macros expanded, so the state values are kept when they expand to the
+// same values. They are kept in engine_params when they were defined there.
+func setReplicationParams(ctx context.Context, d *schema.ResourceData, c *sdk.Client, chTable *models.CHTable, tableResource *models.TableResource) diag.Diagnostics {
+	macros, err := c.GetMacros(ctx)
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
+	macros["database"] = chTable.Database
+	macros["table"] = chTable.Name
+	macros["uuid"] = chTable.UUID
 
-	d.SetId(tableResource.Cluster + ":" + tableResource.Database + ":" + tableResource.Name)
+	statePath := d.Get("zookeeper_path").(string)
+	stateReplica := d.Get("replica_name").(string)
+	stateEngineParams := common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{}))
+	inEngineParams := statePath == "" && len(stateEngineParams) >= 2 && strings.HasPrefix(stateEngineParams[0], "'")
+	if inEngineParams {
+		statePath = strings.Trim(stateEngineParams[0], "'")
+		stateReplica = strings.Trim(stateEngineParams[1], "'")
+	}
 
-	return diags
+	path := tableResource.ZooKeeperPath
+	if models.ExpandMacros(statePath, macros) == models.ExpandMacros(path, macros) {
+		path = statePath
+	}
+	replica := tableResource.ReplicaName
+	if models.ExpandMacros(stateReplica, macros) == models.ExpandMacros(replica, macros) {
+		replica = stateReplica
+	}
+
+	engineParams := tableResource.EngineParams
+	if inEngineParams {
+		engineParams = append([]string{"'" + path + "'", "'" + replica + "'"}, engineParams...)
+	} else {
+		if err := d.Set("zookeeper_path", path); err != nil {
+			return diag.FromErr(fmt.Errorf("setting zookeeper_path: %v", err))
+		}
+		if err := d.Set("replica_name", replica); err != nil {
+			return diag.FromErr(fmt.Errorf("setting replica_name: %v", err))
+		}
+	}
+	if err := d.Set("engine_params", engineParams); err != nil {
+		return diag.FromErr(fmt.Errorf("setting engine_params: %v", err))
+	}
+	return nil
+}
+
+// getConfiguredString returns the value of an attribute only when it's set in the configuration,
+// ignoring the values computed by the server
+func getConfiguredString(d *schema.ResourceData, key string) string {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() {
+		return ""
+	}
+	if value := rawConfig.GetAttr(key); value.IsKnown() && !value.IsNull() {
+		return value.AsString()
+	}
+	return ""
+}
+
+func getDistributedResource(distributed []interface{}) *models.DistributedResource {
+	if len(distributed) == 0 || distributed[0] == nil {
+		return nil
+	}
+	distributedMap := distributed[0].(map[string]interface{})
+	return &models.DistributedResource{
+		Cluster:     distributedMap["cluster"].(string),
+		Database:    distributedMap["database"].(string),
+		Table:       distributedMap["table"].(string),
+		ShardingKey: distributedMap["sharding_key"].(string),
+		PolicyName:  distributedMap["policy_name"].(string),
+	}
+}
+
+func getDistributedDefinitions(distributed *models.DistributedResource) []map[string]interface{} {
+	if distributed == nil {
+		return nil
+	}
+	return []map[string]interface{}{{
+		"cluster":      distributed.Cluster,
+		"database":     distributed.Database,
+		"table":        distributed.Table,
+		"sharding_key": distributed.ShardingKey,
+		"policy_name":  distributed.PolicyName,
+	}}
+}
+
+// isInheritingColumns returns whether the columns of the table are copied from its remote table
+func isInheritingColumns(d resourceGetter) bool {
+	return len(d.Get("distributed").([]interface{})) > 0 && len(d.Get("column").([]interface{})) == 0
+}
+
+func getInheritedColumnDefinitions(columns []models.ColumnDefinition) []map[string]interface{} {
+	definitions := make([]map[string]interface{}, 0, len(columns))
+	for _, column := range columns {
+		definitions = append(definitions, map[string]interface{}{
+			"name": column.Name,
+			"type": column.Type,
+		})
+	}
+	return definitions
+}
+
+// inheritedColumnsEqual compares the inherited columns in state with the current remote table columns
+func inheritedColumnsEqual(inherited []interface{}, columns []models.ColumnDefinition) bool {
+	if len(inherited) != len(columns) {
+		return false
+	}
+	for i, column := range columns {
+		inheritedMap := inherited[i].(map[string]interface{})
+		if inheritedMap["name"].(string) != column.Name || !models.ColumnTypesEqual(inheritedMap["type"].(string), column.Type) {
+			return false
+		}
+	}
+	return true
 }
 
 func resourceTableDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+
+	if d.Get("deletion_protection").(bool) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Table %s.%s is protected against deletion", d.Get("database"), d.Get("name")),
+			Detail:   "Set deletion_protection to false and apply it before destroying or replacing the table.",
+		})
+		return diags
+	}
 
 	var tableResource models.TableResource
 	tableResource.Database = d.Get("database").(string)
@@ -326,7 +900,7 @@ func resourceTableDelete(ctx context.Context, d *schema.ResourceData, meta any)
 	err := c.DeleteTable(ctx, tableResource)
 
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 	return diags
 }
@@ -334,6 +908,7 @@ func resourceTableDelete(ctx context.Context, d *schema.ResourceData, meta any)
 func resourceTableUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	tableResource := models.TableResource{}
 
@@ -343,11 +918,300 @@ func resourceTableUpdate(ctx context.Context, d *schema.ResourceData, meta any)
 	tableResource.SetColumns(d.Get("column").([]interface{}))
 	tableResource.Comment = d.Get("comment").(string)
 	tableResource.TTL = common.MapInterfaceToMapOfString(d.Get("ttl").(map[string]interface{}))
+	tableResource.OrderBy = common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{}))
+	tableResource.SampleBy = d.Get("sample_by").(string)
+
+	if d.HasChanges("database", "name") {
+		oldDatabase, _ := d.GetChange("database")
+		oldName, _ := d.GetChange("name")
+		err := c.RenameTable(ctx, tableResource.Cluster, oldDatabase.(string), oldName.(string), tableResource.Database, tableResource.Name)
+		if err != nil {
+			return sdk.Diagnostics(err)
+		}
+		d.SetId(tableResource.Cluster + ":" + tableResource.Database + ":" + tableResource.Name)
+	}
+
+	if replacementChanges := getReplacementChanges(d); len(replacementChanges) > 0 {
+		tflog.Info(ctx, fmt.Sprintf("Replacing table %s.%s, changed attributes: %v", tableResource.Database, tableResource.Name, replacementChanges))
+		oldColumns, _ := d.GetChange("column")
+		err := c.ReplaceTable(ctx, getTableResource(d), getColumnNames(oldColumns.([]interface{})), common.MapInterfaceToMapOfString(d.Get("copy_column_mapping").(map[string]interface{})))
+		if err != nil {
+			return sdk.Diagnostics(err)
+		}
+		return diags
+	}
+
+	// the table is repaired first, so it can be altered on all the replicas
+	if d.HasChange("divergent_hosts") {
+		if err := c.RepairTable(ctx, getTableResource(d)); err != nil {
+			return sdk.Diagnostics(err)
+		}
+	}
+
+	if d.HasChange("kafka") {
+		settings, resetSettings := getKafkaSettingsChanges(d)
+		if err := c.ModifyTableSettings(ctx, tableResource, settings, resetSettings); err != nil {
+			return sdk.Diagnostics(err)
+		}
+	}
 
 	err := c.UpdateTable(ctx, tableResource, d)
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 
 	return diags
 }
+
+func resourceTableCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if d.Get("check_all_replicas").(bool) && d.NewValueKnown("cluster") && d.Get("cluster").(string) == "" {
+		return fmt.Errorf("check_all_replicas requires cluster")
+	}
+	if err := planDivergentHostsRepair(d); err != nil {
+		return err
+	}
+
+	distributed := getDistributedResource(d.Get("distributed").([]interface{}))
+	if distributed != nil && d.NewValueKnown("engine") && d.Get("engine").(string) != "Distributed" {
+		return fmt.Errorf("distributed is only supported by the Distributed engine, not by %s", d.Get("engine").(string))
+	}
+
+	settings := common.MapInterfaceToMapOfString(d.Get("settings").(map[string]interface{}))
+	if kafka := getKafkaResource(d.Get("kafka").([]interface{})); kafka != nil {
+		if d.NewValueKnown("engine") && d.Get("engine").(string) != "Kafka" {
+			return fmt.Errorf("kafka is only supported by the Kafka engine, not by %s", d.Get("engine").(string))
+		}
+		for key := range kafka.Settings() {
+			if _, ok := settings[key]; ok {
+				return fmt.Errorf("setting %q is managed by the kafka block", key)
+			}
+		}
+		settings = mergeSettings(settings, kafka.Settings())
+	}
+	integration := getIntegrationResource(d.Get("integration").([]interface{}))
+	if integration != nil && d.NewValueKnown("integration") && d.NewValueKnown("engine") && d.NewValueKnown("partition_by") {
+		if err := validateIntegration(d.Get("engine").(string), integration, len(d.Get("partition_by").([]interface{})) > 0); err != nil {
+			return err
+		}
+		for key := range integration.Settings() {
+			if _, ok := settings[key]; ok {
+				return fmt.Errorf("setting %q is managed by the integration block", key)
+			}
+		}
+		settings = mergeSettings(settings, integration.Settings())
+	}
+
+	rawConfig := d.GetRawConfig()
+	configuredPath := !rawConfig.IsNull() && !rawConfig.GetAttr("zookeeper_path").IsNull()
+	if configuredPath && d.NewValueKnown("engine") && !models.IsReplicatedEngine(d.Get("engine").(string)) {
+		return fmt.Errorf("zookeeper_path and replica_name are only supported by Replicated engines, not by %s", d.Get("engine").(string))
+	}
+
+	if isTableEngineDefinitionKnown(d) {
+		engineParams := common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{}))
+		if configuredPath {
+			engineParams = append([]string{d.Get("zookeeper_path").(string), d.Get("replica_name").(string)}, engineParams...)
+		}
+		if distributed != nil {
+			engineParams = distributed.EngineParams()
+		}
+		if integration != nil {
+			engineParams = integration.EngineParams(d.Get("engine").(string))
+		}
+		err := validateTableEngine(tableEngineDefinition{
+			engine:       d.Get("engine").(string),
+			engineParams: engineParams,
+			orderBy:      common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{})),
+			primaryKey:   common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{})),
+			partitionBy:  len(d.Get("partition_by").([]interface{})) > 0,
+			sampleBy:     d.Get("sample_by").(string),
+			ttl:          len(d.Get("ttl").(map[string]interface{})) > 0,
+			indexes:      len(d.Get("index").([]interface{})) > 0,
+			settings:     settings,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if sampleBy := d.Get("sample_by").(string); sampleBy != "" && d.NewValueKnown("primary_key") && d.NewValueKnown("order_by") {
+		primaryKey := common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{}))
+		if len(primaryKey) == 0 {
+			primaryKey = common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{}))
+		}
+		isPrimaryKeyExpression := false
+		for _, expression := range primaryKey {
+			if common.NormalizeExpression(expression) == common.NormalizeExpression(sampleBy) {
+				isPrimaryKeyExpression = true
+			}
+		}
+		if !isPrimaryKeyExpression {
+			return fmt.Errorf("sample_by expression %q must be part of the primary key", sampleBy)
+		}
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	// the columns copied from the remote table are refreshed when the remote table changes
+	if distributed != nil && isInheritingColumns(d) && d.NewValueKnown("distributed") && !d.HasChange("distributed") {
+		inherited := d.Get("inherited_columns").([]interface{})
+		remoteTable, err := meta.(*sdk.Client).GetTable(ctx, distributed.Database, distributed.Table)
+		if err != nil {
+			return fmt.Errorf("reading remote table %s.%s: %v", distributed.Database, distributed.Table, err)
+		}
+		if remoteTable != nil && len(inherited) > 0 {
+			remoteResource, err := remoteTable.ToResource()
+			if err != nil {
+				return fmt.Errorf("transforming remote table %s.%s: %v", distributed.Database, distributed.Table, err)
+			}
+			if !inheritedColumnsEqual(inherited, remoteResource.Columns) {
+				if err := d.SetNew("inherited_columns", getInheritedColumnDefinitions(remoteResource.Columns)); err != nil {
+					return err
+				}
+				if d.Get("replace_strategy").(string) != "copy_and_exchange" {
+					if err := d.ForceNew("inherited_columns"); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if d.Get("replace_strategy").(string) == "copy_and_exchange" {
+		return validateShadowZooKeeperPath(d, configuredPath)
+	}
+	for _, key := range getReplacementChanges(d) {
+		if err := d.ForceNew(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateShadowZooKeeperPath rejects the copy_and_exchange replacements of Replicated tables whose
+// ZooKeeper path doesn't hold the {uuid} macro: the shadow table would be created on the path of the
+// table it replaces. The path can't be derived from the table name either, as the shadow table
+// keeps its path once it's exchanged.
+func validateShadowZooKeeperPath(d *schema.ResourceDiff, configuredPath bool) error {
+	if !d.NewValueKnown("engine") || !models.IsReplicatedEngine(d.Get("engine").(string)) {
+		return nil
+	}
+
+	var path string
+	if configuredPath {
+		if !d.NewValueKnown("zookeeper_path") {
+			return nil
+		}
+		path = d.Get("zookeeper_path").(string)
+	} else if d.NewValueKnown("engine_params") {
+		engineParams := common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{}))
+		if len(engineParams) >= 2 && strings.HasPrefix(engineParams[0], "'") {
+			path = strings.Trim(engineParams[0], "'")
+		}
+	}
+	if path != "" && !strings.Contains(path, "{uuid}") {
+		return fmt.Errorf("replace_strategy copy_and_exchange requires the ZooKeeper path %q to hold the {uuid} macro, so the shadow table gets its own path", path)
+	}
+	return nil
+}
+
+// the engine definition can only be validated once the values computed from other resources are known
+func isTableEngineDefinitionKnown(d *schema.ResourceDiff) bool {
+	for _, key := range []string{"engine", "engine_params", "distributed", "kafka", "integration", "order_by", "primary_key", "partition_by", "sample_by", "ttl", "index", "settings"} {
+		if !d.NewValueKnown(key) {
+			return false
+		}
+	}
+	return true
+}
+
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+type resourceChanges interface {
+	HasChange(key string) bool
+	GetChange(key string) (interface{}, interface{})
+}
+
+// getReplacementChanges returns the changed attributes that can't be altered in place
+func getReplacementChanges(d resourceChanges) []string {
+	var keys []string
+	for _, key := range []string{"engine", "engine_params", "zookeeper_path", "replica_name", "distributed", "integration", "inherited_columns", "primary_key", "partition_by", "settings", "index"} {
+		if d.HasChange(key) {
+			keys = append(keys, key)
+		}
+	}
+	if d.HasChange("kafka") && !isKafkaSettingsChange(d) {
+		keys = append(keys, "kafka")
+	}
+	if d.HasChange("order_by") && !isSortingKeyExtension(d) {
+		keys = append(keys, "order_by")
+	}
+	return keys
+}
+
+// Clickhouse only allows to modify the sorting key in place when appending columns added
+// in the same ALTER query, without default value
+func isSortingKeyExtension(d resourceChanges) bool {
+	oldOrderBy, newOrderBy := d.GetChange("order_by")
+	appended, ok := models.GetOrderByExtension(
+		common.MapArrayInterfaceToArrayOfStrings(oldOrderBy.([]interface{})),
+		common.MapArrayInterfaceToArrayOfStrings(newOrderBy.([]interface{})),
+	)
+	if !ok || len(appended) == 0 {
+		return false
+	}
+
+	oldColumns, newColumns := d.GetChange("column")
+	oldColumnNames := getColumnNames(oldColumns.([]interface{}))
+	for _, expression := range appended {
+		column := findColumn(newColumns.([]interface{}), expression)
+		if column == nil || common.Contains(oldColumnNames, expression) || column["default_kind"].(string) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func getColumnNames(columns []interface{}) []string {
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func findColumn(columns []interface{}, name string) map[string]interface{} {
+	for _, column := range columns {
+		columnMap := column.(map[string]interface{})
+		if columnMap["name"].(string) == name {
+			return columnMap
+		}
+	}
+	return nil
+}
+
+// Clickhouse returns the Nested columns flattened, only the ones declared as Nested are collapsed back
+func getColumnTypes(columns []interface{}) map[string]string {
+	columnTypes := make(map[string]string)
+	for _, column := range columns {
+		columnMap := column.(map[string]interface{})
+		columnTypes[columnMap["name"].(string)] = columnMap["type"].(string)
+	}
+	return columnTypes
+}
+
+func getNestedColumnNames(columns []interface{}) []string {
+	var nestedColumns []string
+	for _, column := range columns {
+		columnMap := column.(map[string]interface{})
+		if _, isNested := models.GetNestedFields(columnMap["type"].(string)); isNested {
+			nestedColumns = append(nestedColumns, columnMap["name"].(string))
+		}
+	}
+	return nestedColumns
+}
@@ -1,6 +1,7 @@
 package resources_test
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -132,3 +133,543 @@ func TestGetCreateStatementForTable(t *testing.T) {
 
 	testutils.RunGetCreateStatementTest(t, "TABLE", testCases)
 }
+
+func TestAccResourceTableCompositeColumns(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: compositeColumnsTableConfig("Nested(name String, value UInt64)"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.composite", "column.#", "5"),
+					resource.TestCheckResourceAttr("clickhouse_table.composite", "column.1.name", "attributes"),
+					resource.TestCheckResourceAttr("clickhouse_table.composite", "column.1.type", "Nested(name String, value UInt64)"),
+					resource.TestCheckResourceAttr("clickhouse_table.composite", "column.2.type", "Map(String,Array(UInt64))"),
+				),
+			},
+			// ADD A FIELD TO THE NESTED COLUMN
+			{
+				Config: compositeColumnsTableConfig("Nested(name String, value UInt64, unit String)"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.composite", "column.#", "5"),
+					resource.TestCheckResourceAttr("clickhouse_table.composite", "column.1.type", "Nested(name String, value UInt64, unit String)"),
+				),
+			},
+		},
+	})
+}
+
+func compositeColumnsTableConfig(nestedType string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "composite_db" {
+		name = "composite_columns_database"
+	}
+
+	resource "clickhouse_table" "composite" {
+		database = clickhouse_db.composite_db.name
+		name = "composite_columns_table"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		column {
+			name = "attributes"
+			type = "%s"
+		}
+		column {
+			name = "labels"
+			type = "Map(String,Array(UInt64))"
+		}
+		column {
+			name = "point"
+			type = "Tuple(x Float64, y Float64)"
+		}
+		column {
+			name = "payload"
+			type = "Variant(UInt64, String)"
+		}
+	}`, nestedType)
+}
+
+func TestAccResourceTableSortingKey(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: sortingKeyTableConfig(`["intHash32(key)"]`, "intHash32(key)", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.sorted", "order_by.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_table.sorted", "sample_by", "intHash32(key)"),
+				),
+			},
+			// APPEND A NEW COLUMN TO THE SORTING KEY IN PLACE
+			{
+				Config: sortingKeyTableConfig(`["intHash32(key)", "version"]`, "intHash32(key)", `
+		column {
+			name = "version"
+			type = "UInt32"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.sorted", "order_by.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.sorted", "order_by.1", "version"),
+					resource.TestCheckResourceAttr("clickhouse_table.sorted", "column.#", "3"),
+				),
+			},
+			// A SAMPLING KEY SPELLED DIFFERENTLY DOESN'T SHOW A DIFF
+			{
+				Config: sortingKeyTableConfig(`["intHash32(key)", "version"]`, "intHash32( key )", `
+		column {
+			name = "version"
+			type = "UInt32"
+		}`),
+				PlanOnly: true,
+			},
+			// CHANGE THE SAMPLING KEY IN PLACE
+			{
+				Config: sortingKeyTableConfig(`["intHash32(key)", "version"]`, "version", `
+		column {
+			name = "version"
+			type = "UInt32"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.sorted", "sample_by", "version"),
+				),
+			},
+		},
+	})
+}
+
+func sortingKeyTableConfig(orderBy string, sampleBy string, extraColumns string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "sorted_db" {
+		name = "sorting_key_database"
+	}
+
+	resource "clickhouse_table" "sorted" {
+		database = clickhouse_db.sorted_db.name
+		name = "sorting_key_table"
+		engine = "MergeTree"
+		order_by = %s
+		sample_by = "%s"
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		column {
+			name = "value"
+			type = "String"
+		}%s
+	}`, orderBy, sampleBy, extraColumns)
+}
+
+func TestAccResourceTableCopyAndExchange(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: copyAndExchangeTableConfig(`["key"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.exchanged", "order_by.#", "1"),
+				),
+			},
+			// CHANGE THE SORTING KEY KEEPING THE DATA
+			{
+				Config: copyAndExchangeTableConfig(`["value", "key"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.exchanged", "order_by.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.exchanged", "order_by.0", "value"),
+					resource.TestCheckResourceAttr("clickhouse_table.exchanged", "column.1.type", "String"),
+				),
+			},
+		},
+	})
+}
+
+func copyAndExchangeTableConfig(orderBy string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "exchanged_db" {
+		name = "copy_and_exchange_database"
+	}
+
+	resource "clickhouse_table" "exchanged" {
+		database = clickhouse_db.exchanged_db.name
+		name = "copy_and_exchange_table"
+		engine = "MergeTree"
+		order_by = %s
+		replace_strategy = "copy_and_exchange"
+		copy_column_mapping = {
+			value = "toString(value)"
+		}
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		column {
+			name = "value"
+			type = "String"
+		}
+	}`, orderBy)
+}
+
+func TestAccResourceTableRename(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: renamedTableConfig("renamed_table"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.renamed", "name", "renamed_table"),
+				),
+			},
+			// RENAME THE TABLE IN PLACE
+			{
+				Config: renamedTableConfig("renamed_table_2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.renamed", "name", "renamed_table_2"),
+					resource.TestCheckResourceAttr("clickhouse_table.renamed", "id", ":rename_database:renamed_table_2"),
+				),
+			},
+		},
+	})
+}
+
+func renamedTableConfig(tableName string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "rename_db" {
+		name = "rename_database"
+	}
+
+	resource "clickhouse_table" "renamed" {
+		database = clickhouse_db.rename_db.name
+		name = "%s"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}`, tableName)
+}
+
+func TestAccResourceTableEngineValidation(t *testing.T) {
+	tableConfig := func(engine string, clauses string) string {
+		return fmt.Sprintf(`
+	resource "clickhouse_table" "invalid" {
+		database = "default"
+		name = "invalid_table"
+		engine = "%s"
+		%s
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}`, engine, clauses)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config:      tableConfig("MergeTree", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("MergeTree engine requires order_by"),
+			},
+			{
+				Config:      tableConfig("Memory", `order_by = ["key"]`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("order_by is only supported by MergeTree engines"),
+			},
+			{
+				Config:      tableConfig("CollapsingMergeTree", `order_by = ["key"]`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("CollapsingMergeTree engine requires at least 1 engine_params"),
+			},
+			{
+				Config:      tableConfig("Kafka", `settings = { max_insert_threads = "1" }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`setting "max_insert_threads" is not a Kafka engine setting`),
+			},
+			{
+				Config: tableConfig("S3", `
+		partition_by {
+			by = "key"
+		}
+		integration {
+			path = "https://bucket.s3.amazonaws.com/data.parquet"
+			format = "Parquet"
+		}`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`partitioned S3 tables require the \{_partition_id\} wildcard`),
+			},
+			{
+				Config:      tableConfig("File", `integration { path = "data.csv" }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("integration path is not supported by the File engine"),
+			},
+			{
+				Config:      tableConfig("Memory", `check_all_replicas = true`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("check_all_replicas requires cluster"),
+			},
+			{
+				Config: tableConfig("ReplicatedMergeTree", `order_by = ["key"]
+		zookeeper_path = "/clickhouse/tables/{shard}/default/invalid_table"
+		replica_name = "{replica}"
+		replace_strategy = "copy_and_exchange"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`replace_strategy copy_and_exchange requires the ZooKeeper path .* to hold the \{uuid\} macro`),
+			},
+		},
+	})
+}
+
+func TestAccResourceTableDistributed(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: distributedTableConfig(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.distributed", "distributed.0.cluster", "default"),
+					resource.TestCheckResourceAttr("clickhouse_table.distributed", "distributed.0.sharding_key", "cityHash64(key)"),
+					resource.TestCheckResourceAttr("clickhouse_table.distributed", "inherited_columns.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.distributed", "column.#", "0"),
+				),
+			},
+			// THE LOCAL TABLE CHANGE IS DETECTED ON THE NEXT PLAN
+			{
+				Config: distributedTableConfig(`
+		column {
+			name = "version"
+			type = "UInt32"
+		}`),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: distributedTableConfig(`
+		column {
+			name = "version"
+			type = "UInt32"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.distributed", "inherited_columns.#", "3"),
+					resource.TestCheckResourceAttr("clickhouse_table.distributed", "inherited_columns.2.name", "version"),
+				),
+			},
+		},
+	})
+}
+
+func distributedTableConfig(extraColumns string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "distributed_db" {
+		name = "distributed_database"
+	}
+
+	resource "clickhouse_table" "local" {
+		database = clickhouse_db.distributed_db.name
+		name = "local_table"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		column {
+			name = "value"
+			type = "String"
+		}%s
+	}
+
+	resource "clickhouse_table" "distributed" {
+		database = clickhouse_db.distributed_db.name
+		name = "distributed_table"
+		engine = "Distributed"
+		distributed {
+			cluster = "default"
+			database = clickhouse_table.local.database
+			table = clickhouse_table.local.name
+			sharding_key = "cityHash64(key)"
+		}
+	}`, extraColumns)
+}
+
+func TestAccResourceTableKafka(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: kafkaTableConfig(1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.kafka", "kafka.0.topic_list.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.kafka", "kafka.0.num_consumers", "1"),
+					resource.TestCheckResourceAttr("clickhouse_table.kafka", "kafka.0.sasl_password", "kafka_password"),
+				),
+			},
+			// MODIFY THE CONSUMERS IN PLACE
+			{
+				Config: kafkaTableConfig(2),
+				Check:  resource.TestCheckResourceAttr("clickhouse_table.kafka", "kafka.0.num_consumers", "2"),
+			},
+		},
+	})
+}
+
+func kafkaTableConfig(numConsumers int) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_table" "kafka" {
+		database = "default"
+		name = "kafka_table"
+		engine = "Kafka"
+		kafka {
+			broker_list = "kafka:9092"
+			topic_list = ["events", "clicks"]
+			group_name = "clickhouse"
+			format = "JSONEachRow"
+			num_consumers = %d
+			security_protocol = "sasl_plaintext"
+			sasl_mechanism = "PLAIN"
+			sasl_username = "kafka_user"
+			sasl_password = "kafka_password"
+		}
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}`, numConsumers)
+}
+
+func TestAccResourceTableIntegration(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "clickhouse_table" "s3" {
+		database = "default"
+		name = "s3_table"
+		engine = "S3"
+		partition_by {
+			by = "key"
+		}
+		integration {
+			path = "https://bucket.s3.amazonaws.com/data/{_partition_id}.parquet"
+			format = "Parquet"
+			access_key_id = "s3_key"
+			secret_access_key = "s3_secret"
+		}
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}
+
+	resource "clickhouse_table" "file" {
+		database = "default"
+		name = "file_table"
+		engine = "File"
+		integration {
+			format = "CSVWithNames"
+		}
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.s3", "integration.0.format", "Parquet"),
+					resource.TestCheckResourceAttr("clickhouse_table.s3", "integration.0.secret_access_key", "s3_secret"),
+					resource.TestCheckResourceAttr("clickhouse_table.s3", "engine_params.#", "0"),
+					resource.TestCheckResourceAttr("clickhouse_table.file", "integration.0.format", "CSVWithNames"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceTableQuerySettings(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				// LowCardinality of a fixed size type is rejected unless allowed by the query settings
+				Config: `
+	resource "clickhouse_table" "query_settings" {
+		database = "default"
+		name = "query_settings_table"
+		engine = "MergeTree"
+		order_by = ["key"]
+		query_settings = {
+			allow_suspicious_low_cardinality_types = "1"
+		}
+		column {
+			name = "key"
+			type = "LowCardinality(UInt64)"
+		}
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.query_settings", "column.0.type", "LowCardinality(UInt64)"),
+					resource.TestCheckResourceAttr("clickhouse_table.query_settings", "query_settings.allow_suspicious_low_cardinality_types", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceTableMutations(t *testing.T) {
+	tableConfig := func(columns string) string {
+		return fmt.Sprintf(`
+	resource "clickhouse_table" "mutations" {
+		database = "default"
+		name = "mutations_table"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		%s
+	}`, columns)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: tableConfig(`
+		column {
+			name = "value"
+			type = "UInt32"
+		}
+		column {
+			name = "dropped"
+			type = "String"
+		}`),
+			},
+			{
+				// the type change and the column drop are applied with mutations, waited for by the update
+				Config: tableConfig(`
+		column {
+			name = "value"
+			type = "UInt64"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.mutations", "column.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.mutations", "column.1.type", "UInt64"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,212 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+// unlimitedParams is the maxParams of the engines accepting any number of params
+const unlimitedParams = -1
+
+// engineSpec describes the definition accepted by a table engine, so configuration errors are
+// reported at plan time instead of failing the CREATE TABLE statement
+type engineSpec struct {
+	minParams int
+	maxParams int
+	// MergeTree engines require a sorting key and are the only ones supporting the partition
+	// key, the sampling key, TTLs and data skipping indexes
+	mergeTree bool
+	// primaryKey is only set for the non MergeTree engines requiring a primary key
+	primaryKey bool
+	// partitionBy is only set for the non MergeTree engines writing a file per partition
+	partitionBy bool
+	// settingPrefixes restricts the settings to the engine specific ones, e.g. kafka_*
+	settingPrefixes []string
+	noSettings      bool
+}
+
+var mergeTreeEngineSpecs = map[string]engineSpec{
+	"MergeTree":                    {minParams: 0, maxParams: 0},
+	"ReplacingMergeTree":           {minParams: 0, maxParams: 2},
+	"SummingMergeTree":             {minParams: 0, maxParams: 1},
+	"AggregatingMergeTree":         {minParams: 0, maxParams: 0},
+	"CollapsingMergeTree":          {minParams: 1, maxParams: 1},
+	"VersionedCollapsingMergeTree": {minParams: 2, maxParams: 2},
+	"GraphiteMergeTree":            {minParams: 1, maxParams: 1},
+}
+
+var engineSpecs = map[string]engineSpec{
+	"Distributed":            {minParams: 3, maxParams: 5},
+	"Kafka":                  {minParams: 0, maxParams: unlimitedParams, settingPrefixes: []string{"kafka_"}},
+	"RabbitMQ":               {minParams: 0, maxParams: unlimitedParams, settingPrefixes: []string{"rabbitmq_"}},
+	"NATS":                   {minParams: 0, maxParams: unlimitedParams, settingPrefixes: []string{"nats_"}},
+	"S3":                     {minParams: 1, maxParams: unlimitedParams, partitionBy: true},
+	"S3Queue":                {minParams: 1, maxParams: unlimitedParams, settingPrefixes: []string{"s3queue_"}},
+	"AzureBlobStorage":       {minParams: 1, maxParams: unlimitedParams, partitionBy: true},
+	"URL":                    {minParams: 1, maxParams: unlimitedParams, partitionBy: true},
+	"File":                   {minParams: 1, maxParams: 2, partitionBy: true},
+	"MySQL":                  {minParams: 1, maxParams: 7},
+	"PostgreSQL":             {minParams: 1, maxParams: 7},
+	"MaterializedPostgreSQL": {minParams: 1, maxParams: 5, settingPrefixes: []string{"materialized_postgresql_"}},
+	"EmbeddedRocksDB":        {minParams: 0, maxParams: 3, primaryKey: true},
+	"Memory":                 {minParams: 0, maxParams: 0},
+	"Log":                    {minParams: 0, maxParams: 0, noSettings: true},
+	"TinyLog":                {minParams: 0, maxParams: 0, noSettings: true},
+	"StripeLog":              {minParams: 0, maxParams: 0, noSettings: true},
+	"Buffer":                 {minParams: 9, maxParams: 12, noSettings: true},
+	"Null":                   {minParams: 0, maxParams: 0, noSettings: true},
+	"Join":                   {minParams: 3, maxParams: unlimitedParams},
+	"Set":                    {minParams: 0, maxParams: 0},
+	"Dictionary":             {minParams: 1, maxParams: 1, noSettings: true},
+}
+
+func init() {
+	for name, spec := range mergeTreeEngineSpecs {
+		spec.mergeTree = true
+		engineSpecs[name] = spec
+		// the replicated variants take the ZooKeeper path and the replica name first, unless
+		// they are left to the default_replica_path and default_replica_name server settings
+		spec.maxParams += 2
+		engineSpecs["Replicated"+name] = spec
+	}
+}
+
+// tableEngineDefinition holds the attributes of a table validated against its engine spec
+type tableEngineDefinition struct {
+	engine       string
+	engineParams []string
+	orderBy      []string
+	primaryKey   []string
+	partitionBy  bool
+	sampleBy     string
+	ttl          bool
+	indexes      bool
+	settings     map[string]string
+}
+
+// validateTableEngine checks the table definition against the spec of its engine. Unknown
+// engines are not validated, they are left to the server.
+func validateTableEngine(definition tableEngineDefinition) error {
+	spec, ok := engineSpecs[definition.engine]
+	if !ok {
+		return nil
+	}
+
+	if len(definition.engineParams) < spec.minParams {
+		return fmt.Errorf("%s engine requires at least %d engine_params, got %d", definition.engine, spec.minParams, len(definition.engineParams))
+	}
+	if spec.maxParams != unlimitedParams && len(definition.engineParams) > spec.maxParams {
+		return fmt.Errorf("%s engine accepts at most %d engine_params, got %d", definition.engine, spec.maxParams, len(definition.engineParams))
+	}
+
+	if spec.mergeTree && len(definition.orderBy) == 0 {
+		return fmt.Errorf("%s engine requires order_by, use [\"tuple()\"] to leave the table unsorted", definition.engine)
+	}
+	if spec.primaryKey && len(definition.primaryKey) == 0 {
+		return fmt.Errorf("%s engine requires primary_key", definition.engine)
+	}
+	if !spec.mergeTree {
+		clauses := []struct {
+			attribute string
+			defined   bool
+		}{
+			{"order_by", len(definition.orderBy) > 0},
+			{"primary_key", len(definition.primaryKey) > 0 && !spec.primaryKey},
+			{"partition_by", definition.partitionBy && !spec.partitionBy},
+			{"sample_by", definition.sampleBy != ""},
+			{"ttl", definition.ttl},
+			{"index", definition.indexes},
+		}
+		for _, clause := range clauses {
+			if clause.defined {
+				return fmt.Errorf("%s is only supported by MergeTree engines, not by %s", clause.attribute, definition.engine)
+			}
+		}
+	}
+
+	if spec.noSettings && len(definition.settings) > 0 {
+		return fmt.Errorf("%s engine doesn't accept settings", definition.engine)
+	}
+	if len(spec.settingPrefixes) > 0 {
+		for setting := range definition.settings {
+			if !hasAnyPrefix(setting, spec.settingPrefixes) {
+				return fmt.Errorf("setting %q is not a %s engine setting, expected %s*", setting, definition.engine, strings.Join(spec.settingPrefixes, "* or "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateIntegration checks the arguments of the integration engines, which are positional
+// unless they override a named collection
+func validateIntegration(engine string, integration *models.IntegrationResource, partitioned bool) error {
+	if !common.Contains(models.IntegrationEngines, engine) {
+		return fmt.Errorf("integration is only supported by the %s engines, not by %s", strings.Join(models.IntegrationEngines, ", "), engine)
+	}
+
+	arguments := []struct {
+		attribute string
+		defined   bool
+		engines   []string
+	}{
+		{"named_collection", integration.NamedCollection != "", []string{"S3", "S3Queue", "AzureBlobStorage", "URL"}},
+		{"endpoint", integration.Endpoint != "", []string{"AzureBlobStorage"}},
+		{"container", integration.Container != "", []string{"AzureBlobStorage"}},
+		{"path", integration.Path != "", []string{"S3", "S3Queue", "AzureBlobStorage", "URL"}},
+		{"access_key_id", integration.AccessKeyID != "", []string{"S3", "S3Queue", "AzureBlobStorage"}},
+		{"mode", integration.Mode != "", []string{"S3Queue"}},
+		{"after_processing", integration.AfterProcessing != "", []string{"S3Queue"}},
+		{"keeper_path", integration.KeeperPath != "", []string{"S3Queue"}},
+	}
+	for _, argument := range arguments {
+		if argument.defined && !common.Contains(argument.engines, engine) {
+			return fmt.Errorf("integration %s is not supported by the %s engine", argument.attribute, engine)
+		}
+	}
+
+	if integration.NamedCollection == "" {
+		required := map[string][]struct {
+			attribute string
+			defined   bool
+		}{
+			"S3":               {{"path", integration.Path != ""}},
+			"S3Queue":          {{"path", integration.Path != ""}, {"format", integration.Format != ""}},
+			"AzureBlobStorage": {{"endpoint", integration.Endpoint != ""}, {"container", integration.Container != ""}, {"path", integration.Path != ""}},
+			"URL":              {{"path", integration.Path != ""}},
+			"File":             {{"format", integration.Format != ""}},
+		}
+		for _, argument := range required[engine] {
+			if !argument.defined {
+				return fmt.Errorf("%s engine requires integration %s", engine, argument.attribute)
+			}
+		}
+		// the positional compression argument follows the format
+		if integration.Compression != "" && integration.Format == "" {
+			return fmt.Errorf("integration compression requires format when no named collection is used")
+		}
+	}
+
+	if engine != "File" && integration.Path != "" {
+		hasWildcard := strings.Contains(integration.Path, models.PartitionWildcard)
+		if partitioned && !hasWildcard {
+			return fmt.Errorf("partitioned %s tables require the %s wildcard in the integration path", engine, models.PartitionWildcard)
+		}
+		if !partitioned && hasWildcard {
+			return fmt.Errorf("the %s wildcard of the integration path requires partition_by", models.PartitionWildcard)
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
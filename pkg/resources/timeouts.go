@@ -0,0 +1,25 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultTimeouts bounds each operation of a resource, including the retries of the transient
+// errors and the wait for the hosts of the ON CLUSTER queries
+func defaultTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(10 * time.Minute),
+		Read:   schema.DefaultTimeout(5 * time.Minute),
+		Update: schema.DefaultTimeout(10 * time.Minute),
+		Delete: schema.DefaultTimeout(10 * time.Minute),
+	}
+}
+
+// the tables replaced with copy_and_exchange copy their data during the update
+func tableTimeouts() *schema.ResourceTimeout {
+	timeouts := defaultTimeouts()
+	timeouts.Update = schema.DefaultTimeout(time.Hour)
+	return timeouts
+}
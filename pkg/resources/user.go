@@ -17,12 +17,23 @@ func ResourceUser() *schema.Resource {
 		UpdateContext: resourceUserUpdate,
 		ReadContext:   resourceUserRead,
 		DeleteContext: resourceUserDelete,
+		CustomizeDiff: resourceUserCustomDiff,
+		Timeouts:      defaultTimeouts(),
 		Schema: map[string]*schema.Schema{
+			"query_settings": querySettingsSchema,
 			"name": {
 				Description: "User name",
 				Type:        schema.TypeString,
 				Required:    true,
 			},
+			"cluster": {
+				Description: "Cluster Name, the user is created with `ON CLUSTER` when set",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"check_all_replicas": checkAllReplicasSchema,
+			"divergent_hosts":    divergentHostsSchema,
 			"password": {
 				Description: "User password",
 				Type:        schema.TypeString,
@@ -52,10 +63,19 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 	}
 
 	if err := d.Set("name", user.Name); err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 	if err := d.Set("roles", &user.Roles); err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
+	}
+	if d.Get("check_all_replicas").(bool) {
+		divergences, err := c.GetUserDivergences(ctx, d.Get("cluster").(string), user.Name)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("reading user on all replicas: %v", err))
+		}
+		if err := setDivergentHosts(ctx, d, divergences); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 	d.SetId(user.Name)
 
@@ -66,12 +86,14 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta any) d
 	var diags diag.Diagnostics
 
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	userName := d.Get("name").(string)
 	password := d.Get("password").(string)
 	rolesSet := d.Get("roles").(*schema.Set)
 	user := models.UserResource{
 		Name:     userName,
+		Cluster:  d.Get("cluster").(string),
 		Password: password,
 		Roles:    rolesSet,
 	}
@@ -89,6 +111,7 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	var diags diag.Diagnostics
 
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	planUserName := d.Get("name").(string)
 	planPassword := d.Get("password").(string)
@@ -97,13 +120,21 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	// After modify original role grants, we need to update default roles
 	user := models.UserResource{
 		Name:     planUserName,
+		Cluster:  d.Get("cluster").(string),
 		Password: planPassword,
 		Roles:    planRoles,
 	}
 
+	// the user is repaired first, so it can be altered on all the replicas
+	if d.HasChange("divergent_hosts") {
+		if err := c.RepairUser(ctx, user); err != nil {
+			return sdk.Diagnostics(err)
+		}
+	}
+
 	chUser, err := c.UpdateUser(ctx, user, d)
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 
 	d.SetId(chUser.Name)
@@ -115,13 +146,21 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta any) d
 	var diags diag.Diagnostics
 
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	userName := d.Get("name").(string)
 
-	err := c.DeleteUser(ctx, userName)
+	err := c.DeleteUser(ctx, userName, d.Get("cluster").(string))
 
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 	return diags
 }
+
+func resourceUserCustomDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("check_all_replicas").(bool) && d.NewValueKnown("cluster") && d.Get("cluster").(string) == "" {
+		return fmt.Errorf("check_all_replicas requires cluster")
+	}
+	return planDivergentHostsRepair(d)
+}
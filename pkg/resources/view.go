@@ -5,12 +5,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/sdk"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func ResourceView() *schema.Resource {
@@ -20,6 +22,9 @@ func ResourceView() *schema.Resource {
 		CreateContext: resourceViewCreate,
 		ReadContext:   resourceViewRead,
 		DeleteContext: resourceViewDelete,
+		UpdateContext: resourceViewUpdate,
+		CustomizeDiff: resourceViewCustomizeDiff,
+		Timeouts:      defaultTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"database": {
 				Description: "DB Name where the view will bellow",
@@ -31,7 +36,6 @@ func ResourceView() *schema.Resource {
 				Description: "View comment, it will be codified in a json along with come metadata information (like cluster name in case of clustering)",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"name": {
 				Description: "View Name",
@@ -39,20 +43,21 @@ func ResourceView() *schema.Resource {
 				Required:    true,
 				ForceNew:    true,
 			},
+			"query_settings": querySettingsSchema,
 			"cluster": {
-				Description: "Cluster Name",
+				Description: "Cluster Name. Clickhouse doesn't record the cluster a view was created on, so it's never read back: it's kept from the state and drift on the cluster isn't detected",
 				Type:        schema.TypeString,
 				Optional:    true,
 				ForceNew:    true,
 				Computed:    true,
 			},
 			"query": {
-				Description: "View query",
+				Description: "View query. Changing it replaces normal views with `CREATE OR REPLACE VIEW` and modifies materialized views with a `to_table` with `ALTER TABLE ... MODIFY QUERY`, other materialized views are recreated",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
-				StateFunc: func(val interface{}) string {
-					return common.NormalizeQuery(val.(string))
+				// previous versions of the provider stored the query lowercased
+				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
+					return oldValue == common.NormalizeQuery(newValue)
 				},
 			},
 			"materialized": {
@@ -62,16 +67,204 @@ func ResourceView() *schema.Resource {
 				ForceNew:    true,
 			},
 			"to_table": {
-				Description: "For materialized view - destination table",
-				Type:        schema.TypeString,
+				Description:   "For materialized view - destination table",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Computed:      true,
+				ConflictsWith: []string{"engine"},
+				// Clickhouse always reads back the table with its database
+				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
+					return newValue != "" && oldValue == d.Get("database").(string)+"."+newValue
+				},
+			},
+			"engine": {
+				Description:   "For materialized view without destination table - definition of the inner table storing the data",
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"to_table"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"engine": {
+							Description: "Inner table engine type",
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+						},
+						"engine_params": {
+							Description: "Engine params in case the engine type requires them",
+							Type:        schema.TypeList,
+							Optional:    true,
+							ForceNew:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"order_by": {
+							Description: "Order by columns to use as sorting key",
+							Type:        schema.TypeList,
+							Optional:    true,
+							ForceNew:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"partition_by": {
+							Description: "Partition Key to split data",
+							Type:        schema.TypeList,
+							Optional:    true,
+							ForceNew:    true,
+							Elem:        partitionByElem(),
+						},
+						"primary_key": {
+							Description: "Columns to use as primary key",
+							Type:        schema.TypeList,
+							Optional:    true,
+							ForceNew:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"settings": {
+							Description: "Inner table settings",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							ForceNew:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"populate": {
+				Description: "For materialized view with an inner engine - fill the view with the existing data of the source table on creation. Rows inserted during the population are not included",
+				Type:        schema.TypeBool,
 				Optional:    true,
 				ForceNew:    true,
+				Default:     false,
+			},
+			"sql_security": {
+				Description:  "SQL security of the view, one of `DEFINER`, `INVOKER` or `NONE`. Materialized views don't support `INVOKER`. Requires Clickhouse 24.2 or later",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"DEFINER", "INVOKER", "NONE"}, false),
+			},
+			"definer": {
+				Description: "User whose privileges are used to run the query of the view when `sql_security` is `DEFINER`. Requires Clickhouse 24.2 or later",
+				Type:        schema.TypeString,
+				Optional:    true,
 				Computed:    true,
+				// CURRENT_USER is read back as the name of the user
+				DiffSuppressFunc: func(k, oldValue, newValue string, d *schema.ResourceData) bool {
+					return strings.EqualFold(newValue, "CURRENT_USER") && oldValue != ""
+				},
+			},
+			"column": {
+				Description: "Columns of the view, inferred from the query when not defined. Changing them recreates materialized views",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Column Name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"type": {
+							Description: "Column Type",
+							Type:        schema.TypeString,
+							Required:    true,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return models.ColumnTypesEqual(old, new)
+							},
+						},
+					},
+				},
+			},
+			"refresh": {
+				Description: "For materialized view - refresh the view periodically by running its query, instead of on each insert into the source table",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"every": {
+							Description:      "Refresh the view at a fixed interval aligned to the calendar, e.g. `1 HOUR`",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ExactlyOneOf:     []string{"refresh.0.every", "refresh.0.after"},
+							DiffSuppressFunc: suppressIntervalDiff,
+						},
+						"after": {
+							Description:      "Refresh the view after the given interval since the previous refresh, e.g. `30 MINUTE`",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ExactlyOneOf:     []string{"refresh.0.every", "refresh.0.after"},
+							DiffSuppressFunc: suppressIntervalDiff,
+						},
+						"offset": {
+							Description:      "Offset of the refresh time in the `every` interval, e.g. `5 MINUTE`",
+							Type:             schema.TypeString,
+							Optional:         true,
+							RequiredWith:     []string{"refresh.0.every"},
+							DiffSuppressFunc: suppressIntervalDiff,
+						},
+						"randomize_for": {
+							Description:      "Randomly delay each refresh up to the given interval",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressIntervalDiff,
+						},
+						"depends_on": {
+							Description: "Refreshable materialized views (`database.name`) that must be refreshed before this view",
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"append": {
+							Description: "Append the refreshed rows to the destination table instead of replacing its content",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							ForceNew:    true,
+							Default:     false,
+						},
+						"settings": {
+							Description: "Refresh settings, like `refresh_retries`",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"status": {
+							Description: "Current status of the refresh",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"next_refresh_time": {
+							Description: "Time of the next scheduled refresh",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
+// intervals are normalized by Clickhouse, e.g. `1 hour` is read back as `1 HOUR`
+func suppressIntervalDiff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(oldValue), " "), strings.Join(strings.Fields(newValue), " "))
+}
+
 func resourceViewRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	writer := bufio.NewWriter(os.Stdout)
 
@@ -106,14 +299,13 @@ func resourceViewRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 		return diag.FromErr(fmt.Errorf("setting name: %v", err))
 	}
 
-	if viewResource.Cluster != "" {
-		if err := d.Set("cluster", viewResource.Cluster); err != nil {
-			return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
+	// not set - cluster
+	// the query is formatted by Clickhouse, so it's only read back when it's not equivalent to the state
+	if !c.QueriesEqual(ctx, d.Get("query").(string), viewResource.Query) {
+		if err := d.Set("query", viewResource.Query); err != nil {
+			return diag.FromErr(fmt.Errorf("setting query: %v", err))
 		}
 	}
-	if err := d.Set("query", viewResource.Query); err != nil {
-		return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
-	}
 	if err := d.Set("materialized", viewResource.Materialized); err != nil {
 		return diag.FromErr(fmt.Errorf("setting materialized: %v", err))
 	}
@@ -122,14 +314,132 @@ func resourceViewRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 			return diag.FromErr(fmt.Errorf("setting to_table: %v", err))
 		}
 	}
+	if viewResource.Materialized && d.Get("to_table").(string) == "" {
+		innerTable, err := c.GetViewInnerTable(ctx, *chView)
+		if err != nil {
+			return sdk.Diagnostics(err)
+		}
+		if innerTable != nil {
+			if err := d.Set("engine", getViewEngineDefinition(innerTable.ToEngineResource(), d)); err != nil {
+				return diag.FromErr(fmt.Errorf("setting engine: %v", err))
+			}
+		}
+	}
 
-	d.SetId(viewResource.Cluster + ":" + database + ":" + viewName)
+	if viewResource.SQLSecurity != "" {
+		if err := d.Set("sql_security", viewResource.SQLSecurity); err != nil {
+			return diag.FromErr(fmt.Errorf("setting sql_security: %v", err))
+		}
+	}
+	if viewResource.Definer != "" {
+		if err := d.Set("definer", viewResource.Definer); err != nil {
+			return diag.FromErr(fmt.Errorf("setting definer: %v", err))
+		}
+	}
+	if err := d.Set("column", getViewColumnDefinitions(viewResource.Columns)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting column: %v", err))
+	}
+	if viewResource.Refresh != nil {
+		refreshStatus, err := c.GetViewRefresh(ctx, database, viewName)
+		if err != nil {
+			return sdk.Diagnostics(err)
+		}
+		if err := d.Set("refresh", getViewRefreshDefinition(viewResource.Refresh, refreshStatus, d)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting refresh: %v", err))
+		}
+	} else if err := d.Set("refresh", nil); err != nil {
+		return diag.FromErr(fmt.Errorf("setting refresh: %v", err))
+	}
+
+	d.SetId(d.Get("cluster").(string) + ":" + database + ":" + viewName)
 
 	return diags
 }
 
 func resourceViewCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+	viewResource := getViewResource(d)
+
+	diags := viewResource.Validate()
+	if diags.HasError() {
+		return diags
+	}
+
+	err := c.CreateView(ctx, viewResource)
+
+	if err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	d.SetId(viewResource.Cluster + ":" + viewResource.Database + ":" + viewResource.Name)
+
+	return diags
+}
+
+func resourceViewUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
+
+	viewResource := getViewResource(d)
+
+	err := c.UpdateView(ctx, viewResource, d)
+	if err != nil {
+		return sdk.Diagnostics(err)
+	}
+
+	return diags
+}
+
+// the query of a materialized view can only be modified in place when it writes to a
+// TO table, otherwise the inner table would have to change along with it
+func resourceViewCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	rawConfig := d.GetRawConfig()
+	configuredSQLSecurity := !rawConfig.IsNull() && !rawConfig.GetAttr("sql_security").IsNull()
+	configuredDefiner := !rawConfig.IsNull() && !rawConfig.GetAttr("definer").IsNull()
+	if configuredSQLSecurity && d.Get("sql_security").(string) == "INVOKER" && d.Get("materialized").(bool) {
+		return fmt.Errorf("sql_security INVOKER is not supported by materialized views")
+	}
+	if configuredSQLSecurity && configuredDefiner && d.Get("sql_security").(string) != "DEFINER" {
+		return fmt.Errorf("definer requires sql_security DEFINER, got %s", d.Get("sql_security").(string))
+	}
+	// the server version is only checked when the attributes are configured and planned to change
+	if (configuredSQLSecurity || configuredDefiner) && (d.Id() == "" || d.HasChanges("sql_security", "definer")) {
+		supported, err := meta.(*sdk.Client).ServerVersionAtLeast(ctx, 24, 2)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return fmt.Errorf("sql_security and definer require Clickhouse 24.2 or later")
+		}
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+	// the columns of a materialized view are those of its target table, they can't be altered
+	if d.HasChange("column") && d.Get("materialized").(bool) {
+		if err := d.ForceNew("column"); err != nil {
+			return err
+		}
+	}
+	// the schedule of a refreshable view can be modified, but a view can't become refreshable or stop being so
+	if d.HasChange("refresh") {
+		oldRefresh, newRefresh := d.GetChange("refresh")
+		if len(oldRefresh.([]interface{})) != len(newRefresh.([]interface{})) {
+			if err := d.ForceNew("refresh"); err != nil {
+				return err
+			}
+		}
+	}
+	if d.HasChange("query") && d.Get("materialized").(bool) && d.Get("to_table").(string) == "" {
+		return d.ForceNew("query")
+	}
+	return nil
+}
+
+func getViewResource(d *schema.ResourceData) models.ViewResource {
 	viewResource := models.ViewResource{}
 
 	viewResource.Cluster = d.Get("cluster").(string)
@@ -139,26 +449,107 @@ func resourceViewCreate(ctx context.Context, d *schema.ResourceData, meta any) d
 	viewResource.Materialized = d.Get("materialized").(bool)
 	viewResource.ToTable = d.Get("to_table").(string)
 	viewResource.Comment = d.Get("comment").(string)
+	viewResource.Populate = d.Get("populate").(bool)
+	viewResource.SQLSecurity = d.Get("sql_security").(string)
+	viewResource.Definer = d.Get("definer").(string)
 
-	diags := viewResource.Validate()
-	if diags.HasError() {
-		return diags
+	// columns inferred from a previous query must not be kept when the query changes
+	columns := d.Get("column").([]interface{})
+	if rawConfig := d.GetRawConfig(); !rawConfig.IsNull() {
+		if columnsConfig := rawConfig.GetAttr("column"); columnsConfig.IsNull() || columnsConfig.IsKnown() && columnsConfig.LengthInt() == 0 {
+			columns = nil
+		}
+	}
+	for _, column := range columns {
+		columnMap := column.(map[string]interface{})
+		viewResource.Columns = append(viewResource.Columns, models.ColumnDefinition{
+			Name: columnMap["name"].(string),
+			Type: columnMap["type"].(string),
+		})
 	}
 
-	err := c.CreateView(ctx, viewResource)
+	for _, engine := range d.Get("engine").([]interface{}) {
+		engineMap := engine.(map[string]interface{})
+		viewResource.Engine = &models.ViewEngineResource{
+			Engine:       engineMap["engine"].(string),
+			EngineParams: common.MapArrayInterfaceToArrayOfStrings(engineMap["engine_params"].([]interface{})),
+			OrderBy:      common.MapArrayInterfaceToArrayOfStrings(engineMap["order_by"].([]interface{})),
+			PartitionBy:  models.GetPartitionBy(engineMap["partition_by"].([]interface{})),
+			PrimaryKey:   common.MapArrayInterfaceToArrayOfStrings(engineMap["primary_key"].([]interface{})),
+			Settings:     common.MapInterfaceToMapOfString(engineMap["settings"].(map[string]interface{})),
+		}
+	}
 
-	if err != nil {
-		return diag.FromErr(err)
+	for _, refresh := range d.Get("refresh").([]interface{}) {
+		refreshMap := refresh.(map[string]interface{})
+		viewResource.Refresh = &models.ViewRefreshResource{
+			Every:        refreshMap["every"].(string),
+			After:        refreshMap["after"].(string),
+			Offset:       refreshMap["offset"].(string),
+			RandomizeFor: refreshMap["randomize_for"].(string),
+			DependsOn:    common.MapArrayInterfaceToArrayOfStrings(refreshMap["depends_on"].([]interface{})),
+			Append:       refreshMap["append"].(bool),
+			Settings:     common.MapInterfaceToMapOfString(refreshMap["settings"].(map[string]interface{})),
+		}
 	}
 
-	d.SetId(viewResource.Cluster + ":" + viewResource.Database + ":" + viewResource.Name)
+	return viewResource
+}
 
-	return diags
+// the partition key and the settings of the inner table are not read back, they are kept from the state
+func getViewEngineDefinition(engine *models.ViewEngineResource, d *schema.ResourceData) []map[string]interface{} {
+	definition := map[string]interface{}{
+		"engine":        engine.Engine,
+		"engine_params": engine.EngineParams,
+		"order_by":      engine.OrderBy,
+	}
+	if stateEngine := d.Get("engine").([]interface{}); len(stateEngine) > 0 && stateEngine[0] != nil {
+		stateEngineMap := stateEngine[0].(map[string]interface{})
+		definition["partition_by"] = stateEngineMap["partition_by"]
+		definition["settings"] = stateEngineMap["settings"]
+		// the primary key defaults to the sorting key, so it's only read back when defined explicitly
+		if len(stateEngineMap["primary_key"].([]interface{})) > 0 {
+			definition["primary_key"] = engine.PrimaryKey
+		}
+	}
+	return []map[string]interface{}{definition}
+}
+
+func getViewColumnDefinitions(columns []models.ColumnDefinition) []map[string]interface{} {
+	definitions := make([]map[string]interface{}, 0, len(columns))
+	for _, column := range columns {
+		definitions = append(definitions, map[string]interface{}{
+			"name": column.Name,
+			"type": column.Type,
+		})
+	}
+	return definitions
+}
+
+// the refresh settings are not part of the view metadata, they are kept from the state
+func getViewRefreshDefinition(refresh *models.ViewRefreshResource, refreshStatus *models.CHViewRefresh, d *schema.ResourceData) []map[string]interface{} {
+	definition := map[string]interface{}{
+		"every":         refresh.Every,
+		"after":         refresh.After,
+		"offset":        refresh.Offset,
+		"randomize_for": refresh.RandomizeFor,
+		"depends_on":    refresh.DependsOn,
+		"append":        refresh.Append,
+	}
+	if refreshStatus != nil {
+		definition["status"] = refreshStatus.Status
+		definition["next_refresh_time"] = refreshStatus.NextRefreshTime
+	}
+	if stateRefresh := d.Get("refresh").([]interface{}); len(stateRefresh) > 0 && stateRefresh[0] != nil {
+		definition["settings"] = stateRefresh[0].(map[string]interface{})["settings"]
+	}
+	return []map[string]interface{}{definition}
 }
 
 func resourceViewDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	c := meta.(*sdk.Client)
+	ctx = withQuerySettings(ctx, d)
 
 	var viewResource models.ViewResource
 	viewResource.Database = d.Get("database").(string)
@@ -168,7 +559,7 @@ func resourceViewDelete(ctx context.Context, d *schema.ResourceData, meta any) d
 	err := c.DeleteView(ctx, viewResource)
 
 	if err != nil {
-		return diag.FromErr(err)
+		return sdk.Diagnostics(err)
 	}
 	return diags
 }
@@ -0,0 +1,288 @@
+package resources_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceView(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: viewConfig("SELECT key FROM view_database.events", "This is a view"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.view", "materialized", "false"),
+					resource.TestCheckResourceAttr("clickhouse_view.materialized_view", "materialized", "true"),
+					resource.TestCheckResourceAttr("clickhouse_view.materialized_view", "to_table", "view_database.events_copy"),
+				),
+			},
+			// UPDATE THE QUERIES IN PLACE
+			{
+				Config: viewConfig("SELECT key, value FROM view_database.events", "This is an updated view"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.view", "query", "SELECT key, value FROM view_database.events"),
+					resource.TestCheckResourceAttr("clickhouse_view.materialized_view", "to_table", "view_database.events_copy"),
+				),
+			},
+		},
+	})
+}
+
+func viewConfig(query string, comment string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "view_db" {
+		name = "view_database"
+	}
+
+	resource "clickhouse_table" "events" {
+		database = clickhouse_db.view_db.name
+		name = "events"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		column {
+			name = "value"
+			type = "String"
+		}
+	}
+
+	resource "clickhouse_table" "events_copy" {
+		database = clickhouse_db.view_db.name
+		name = "events_copy"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+		column {
+			name = "value"
+			type = "String"
+		}
+	}
+
+	resource "clickhouse_view" "view" {
+		database = clickhouse_db.view_db.name
+		name = "events_view"
+		materialized = false
+		query = "%[1]s"
+		comment = "%[2]s"
+		depends_on = [clickhouse_table.events]
+	}
+
+	resource "clickhouse_view" "materialized_view" {
+		database = clickhouse_db.view_db.name
+		name = "events_materialized_view"
+		materialized = true
+		to_table = "view_database.events_copy"
+		query = "%[1]s"
+		depends_on = [clickhouse_table.events, clickhouse_table.events_copy]
+	}`, query, comment)
+}
+
+func TestAccResourceViewInnerEngine(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "clickhouse_db" "inner_engine_db" {
+		name = "inner_engine_database"
+	}
+
+	resource "clickhouse_table" "source" {
+		database = clickhouse_db.inner_engine_db.name
+		name = "source"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}
+
+	resource "clickhouse_view" "inner_engine" {
+		database = clickhouse_db.inner_engine_db.name
+		name = "inner_engine_view"
+		materialized = true
+		populate = true
+		query = "SELECT key, count() AS total FROM inner_engine_database.source GROUP BY key"
+		engine {
+			engine = "SummingMergeTree"
+			order_by = ["key"]
+			settings = {
+				index_granularity = "4096"
+			}
+		}
+		depends_on = [clickhouse_table.source]
+	}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.inner_engine", "engine.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_view.inner_engine", "engine.0.engine", "SummingMergeTree"),
+					resource.TestCheckResourceAttr("clickhouse_view.inner_engine", "engine.0.order_by.0", "key"),
+					resource.TestCheckResourceAttr("clickhouse_view.inner_engine", "engine.0.settings.index_granularity", "4096"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceViewRefresh(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: viewRefreshConfig("1 HOUR"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.refreshable", "refresh.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_view.refreshable", "refresh.0.every", "1 HOUR"),
+					resource.TestCheckResourceAttrSet("clickhouse_view.refreshable", "refresh.0.status"),
+				),
+			},
+			// MODIFY THE SCHEDULE IN PLACE
+			{
+				Config: viewRefreshConfig("1 DAY"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.refreshable", "refresh.0.every", "1 DAY"),
+				),
+			},
+		},
+	})
+}
+
+func viewRefreshConfig(every string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "refresh_db" {
+		name = "refresh_database"
+	}
+
+	resource "clickhouse_table" "events" {
+		database = clickhouse_db.refresh_db.name
+		name = "events"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}
+
+	resource "clickhouse_table" "totals" {
+		database = clickhouse_db.refresh_db.name
+		name = "totals"
+		engine = "MergeTree"
+		order_by = ["total"]
+		column {
+			name = "total"
+			type = "UInt64"
+		}
+	}
+
+	resource "clickhouse_view" "refreshable" {
+		database = clickhouse_db.refresh_db.name
+		name = "totals_view"
+		materialized = true
+		to_table = "refresh_database.totals"
+		query = "SELECT count() AS total FROM refresh_database.events"
+		refresh {
+			every = "%s"
+		}
+		depends_on = [clickhouse_table.events, clickhouse_table.totals]
+	}`, every)
+}
+
+func TestAccResourceViewSQLSecurity(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: viewSQLSecurityConfig(`sql_security = "INVOKER"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.secured", "sql_security", "INVOKER"),
+					resource.TestCheckResourceAttr("clickhouse_view.secured", "column.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_view.secured", "column.0.name", "id"),
+				),
+			},
+			// CHANGE THE SQL SECURITY IN PLACE
+			{
+				Config: viewSQLSecurityConfig(`sql_security = "DEFINER"
+		definer = "CURRENT_USER"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.secured", "sql_security", "DEFINER"),
+					resource.TestCheckResourceAttr("clickhouse_view.secured", "definer", "default"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceViewSQLSecurityValidation(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "clickhouse_view" "invalid" {
+		database = "default"
+		name = "invalid_view"
+		materialized = true
+		to_table = "default.events"
+		query = "SELECT key FROM default.source"
+		sql_security = "INVOKER"
+	}`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("sql_security INVOKER is not supported by materialized views"),
+			},
+			{
+				Config: viewSQLSecurityConfig(`sql_security = "NONE"
+		definer = "default"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("definer requires sql_security DEFINER"),
+			},
+		},
+	})
+}
+
+func viewSQLSecurityConfig(security string) string {
+	return fmt.Sprintf(`
+	resource "clickhouse_db" "security_db" {
+		name = "security_database"
+	}
+
+	resource "clickhouse_table" "events" {
+		database = clickhouse_db.security_db.name
+		name = "events"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "UInt64"
+		}
+	}
+
+	resource "clickhouse_view" "secured" {
+		database = clickhouse_db.security_db.name
+		name = "secured_view"
+		materialized = false
+		query = "SELECT key FROM security_database.events"
+		%s
+		column {
+			name = "id"
+			type = "UInt64"
+		}
+		depends_on = [clickhouse_table.events]
+	}`, security)
+}
@@ -1,9 +1,55 @@
 package sdk
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
+const (
+	DropModeDrop      = "drop"
+	DropModeDetach    = "detach"
+	DropModeGraveyard = "graveyard"
+)
+
+// DropPolicy defines how tables and databases are removed when their resources are destroyed
+type DropPolicy struct {
+	AllowDrop          bool
+	Mode               string
+	GraveyardDatabase  string
+	GraveyardRetention time.Duration
+}
+
 type Client struct {
-	Conn driver.Conn
+	Conn           driver.Conn
+	DropPolicy     DropPolicy
+	DistributedDDL DistributedDDL
+	RetryPolicy    RetryPolicy
+	MutationPolicy MutationPolicy
+}
+
+// ServerVersionAtLeast tells whether the Clickhouse server version is greater or equal than major.minor
+func (c *Client) ServerVersionAtLeast(ctx context.Context, major int, minor int) (bool, error) {
+	var version string
+	if err := c.Conn.QueryRow(ctx, "SELECT version()").Scan(&version); err != nil {
+		return false, fmt.Errorf("reading Clickhouse server version: %v", err)
+	}
+
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false, fmt.Errorf("unexpected Clickhouse server version %q", version)
+	}
+	serverMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false, fmt.Errorf("unexpected Clickhouse server version %q", version)
+	}
+	serverMinor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, fmt.Errorf("unexpected Clickhouse server version %q", version)
+	}
+	return serverMajor > major || serverMajor == major && serverMinor >= minor, nil
 }
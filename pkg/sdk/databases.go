@@ -3,7 +3,9 @@ package sdk
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
 )
 
@@ -27,3 +29,65 @@ func (c *Client) GetDBTables(ctx context.Context, database string) ([]models.CHT
 
 	return tables, nil
 }
+
+// CreateDatabase creates a database, with the server default engine unless one is defined
+func (c *Client) CreateDatabase(ctx context.Context, database models.DatabaseResource) error {
+	query := fmt.Sprintf(
+		"%s %v %v %s %s COMMENT '%v'",
+		common.GetCreateStatement("database"),
+		database.Name,
+		common.GetClusterStatement(database.Cluster),
+		buildDatabaseEngineSentence(database.Engine, database.EngineParams),
+		buildSettingsSentence(database.Settings),
+		database.Comment,
+	)
+	// the query isn't part of the error as the engine params may hold credentials
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("creating database %s: %w", database.Name, err)
+	}
+	return nil
+}
+
+// engines without params, like Atomic, don't accept an empty list of arguments
+func buildDatabaseEngineSentence(engine string, engineParams []string) string {
+	if engine == "" {
+		return ""
+	}
+	if len(engineParams) == 0 {
+		return fmt.Sprintf("ENGINE = %s", engine)
+	}
+	return fmt.Sprintf("ENGINE = %s(%s)", engine, strings.Join(engineParams, ", "))
+}
+
+// RenameDatabase renames a database and moves all its tables along with it, it's only
+// supported by the Atomic engine
+func (c *Client) RenameDatabase(ctx context.Context, cluster string, oldName string, newName string) error {
+	query := fmt.Sprintf("RENAME DATABASE %s TO %s %s", oldName, newName, common.GetClusterStatement(cluster))
+	if err := executeQueryWithoutRetry(ctx, c, query, nil); err != nil {
+		return fmt.Errorf("renaming database %s: %w", oldName, err)
+	}
+	return nil
+}
+
+// UpdateDatabaseComment modifies the comment of a database in place
+func (c *Client) UpdateDatabaseComment(ctx context.Context, cluster string, name string, comment string) error {
+	query := fmt.Sprintf("ALTER DATABASE %s %s MODIFY COMMENT '%s'", name, common.GetClusterStatement(cluster), comment)
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("modifying comment of database %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteDatabase removes an empty database according to the provider drop policy. As a database
+// can't be moved into another one, the graveyard mode drops it like the default mode.
+func (c *Client) DeleteDatabase(ctx context.Context, cluster string, name string) error {
+	if !c.DropPolicy.AllowDrop {
+		return fmt.Errorf("database %s can't be deleted, dropping databases is disabled by the provider allow_drop setting", name)
+	}
+
+	query := fmt.Sprintf("DROP DATABASE %v %v SYNC", name, common.GetClusterStatement(cluster))
+	if c.DropPolicy.Mode == DropModeDetach {
+		query = fmt.Sprintf("DETACH DATABASE %v %v PERMANENTLY SYNC", name, common.GetClusterStatement(cluster))
+	}
+	return executeQuery(ctx, c, query)
+}
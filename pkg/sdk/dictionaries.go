@@ -0,0 +1,67 @@
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func (c *Client) GetDictionary(ctx context.Context, database string, name string) (*models.CHDictionary, error) {
+	query := fmt.Sprintf(
+		`SELECT database, name, toString(status) AS status, key.names AS key_names, key.types AS key_types,
+		attribute.names AS attribute_names, attribute.types AS attribute_types, lifetime_min, lifetime_max, last_exception, comment
+		FROM system.dictionaries WHERE database = '%s' AND name = '%s'`,
+		database,
+		name,
+	)
+	row := c.Conn.QueryRow(ctx, query)
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading dictionary from Clickhouse: %v", row.Err())
+	}
+
+	var chDictionary models.CHDictionary
+	err := row.ScanStruct(&chDictionary)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse dictionary row: %v", err)
+	}
+	return &chDictionary, nil
+}
+
+func (c *Client) CreateDictionary(ctx context.Context, dictionary models.DictionaryResource) error {
+	if err := executeQuery(ctx, c, buildCreateDictionarySentence("CREATE", dictionary)); err != nil {
+		return fmt.Errorf("creating Clickhouse dictionary: %w", err)
+	}
+	return nil
+}
+
+// ReplaceDictionary applies a new definition to a dictionary, the dictionary stays available
+// with its previous content until it's loaded again
+func (c *Client) ReplaceDictionary(ctx context.Context, dictionary models.DictionaryResource) error {
+	if err := executeQuery(ctx, c, buildCreateDictionarySentence("CREATE OR REPLACE", dictionary)); err != nil {
+		return fmt.Errorf("replacing Clickhouse dictionary: %w", err)
+	}
+	return nil
+}
+
+// ReloadDictionary loads the dictionary data from its source again
+func (c *Client) ReloadDictionary(ctx context.Context, dictionary models.DictionaryResource) error {
+	query := fmt.Sprintf("SYSTEM RELOAD DICTIONARY %s.%s %s", dictionary.Database, dictionary.Name, common.GetClusterStatement(dictionary.Cluster))
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("reloading Clickhouse dictionary: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteDictionary(ctx context.Context, dictionary models.DictionaryResource) error {
+	query := fmt.Sprintf("DROP DICTIONARY IF EXISTS %s.%s %s SYNC", dictionary.Database, dictionary.Name, common.GetClusterStatement(dictionary.Cluster))
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("deleting Clickhouse dictionary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func buildCreateDictionarySentence(createStatement string, dictionary models.DictionaryResource) string {
+	return fmt.Sprintf(
+		"%s DICTIONARY %s.%s %s (%s) PRIMARY KEY %s SOURCE(%s) LAYOUT(%s) LIFETIME(MIN %d MAX %d) %s COMMENT '%s'",
+		createStatement,
+		dictionary.Database,
+		dictionary.Name,
+		common.GetClusterStatement(dictionary.Cluster),
+		buildDictionaryAttributes(dictionary.Attributes),
+		strings.Join(dictionary.PrimaryKey, ", "),
+		buildDictionaryParameters(strings.ToUpper(dictionary.Source.Type), dictionary.Source.Parameters, true),
+		buildDictionaryParameters(strings.ToUpper(dictionary.Layout.Type), dictionary.Layout.Parameters, false),
+		dictionary.LifetimeMin,
+		dictionary.LifetimeMax,
+		buildDictionaryRange(dictionary.Range),
+		dictionary.Comment,
+	)
+}
+
+func buildDictionaryAttributes(attributes []models.DictionaryAttribute) string {
+	definitions := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		definition := fmt.Sprintf("`%s` %s", attribute.Name, attribute.Type)
+		if attribute.Default != "" {
+			definition += " DEFAULT " + attribute.Default
+		}
+		if attribute.Expression != "" {
+			definition += " EXPRESSION " + attribute.Expression
+		}
+		if attribute.Hierarchical {
+			definition += " HIERARCHICAL"
+		}
+		if attribute.Injective {
+			definition += " INJECTIVE"
+		}
+		definitions = append(definitions, definition)
+	}
+	return strings.Join(definitions, ", ")
+}
+
+// numericDictionarySourceParameters are the source parameters Clickhouse expects as numbers
+var numericDictionarySourceParameters = []string{"PORT", "SECURE", "DB_INDEX", "POOL_SIZE", "PRIORITY"}
+
+// buildDictionaryParameters builds the `NAME(KEY value ...)` syntax shared by sources and layouts.
+// Source parameters are quoted, as they are mostly strings, except the numeric ones such as `PORT`.
+// Layout parameters are always numbers
+func buildDictionaryParameters(name string, parameters map[string]string, quoted bool) string {
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var definitions []string
+	for _, key := range keys {
+		value := parameters[key]
+		if quoted && !common.Contains(numericDictionarySourceParameters, strings.ToUpper(key)) {
+			value = fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(value, "\\", "\\\\"), "'", "\\'"))
+		}
+		definitions = append(definitions, fmt.Sprintf("%s %s", strings.ToUpper(key), value))
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(definitions, " "))
+}
+
+func buildDictionaryRange(dictionaryRange *models.DictionaryRange) string {
+	if dictionaryRange == nil {
+		return ""
+	}
+	return fmt.Sprintf("RANGE(MIN %s MAX %s)", dictionaryRange.Min, dictionaryRange.Max)
+}
@@ -0,0 +1,254 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const (
+	DistributedDDLOutputModeThrow                         = "throw"
+	DistributedDDLOutputModeNone                          = "none"
+	DistributedDDLOutputModeNullStatusOnTimeout           = "null_status_on_timeout"
+	DistributedDDLOutputModeNeverThrow                    = "never_throw"
+	DistributedDDLOutputModeNoneOnlyActive                = "none_only_active"
+	DistributedDDLOutputModeNullStatusOnTimeoutOnlyActive = "null_status_on_timeout_only_active"
+	DistributedDDLOutputModeThrowOnlyActive               = "throw_only_active"
+)
+
+var DistributedDDLOutputModes = []string{
+	DistributedDDLOutputModeThrow,
+	DistributedDDLOutputModeNone,
+	DistributedDDLOutputModeNullStatusOnTimeout,
+	DistributedDDLOutputModeNeverThrow,
+	DistributedDDLOutputModeNoneOnlyActive,
+	DistributedDDLOutputModeNullStatusOnTimeoutOnlyActive,
+	DistributedDDLOutputModeThrowOnlyActive,
+}
+
+// DistributedDDL configures how long the ON CLUSTER queries wait for the hosts of the cluster,
+// and what they return when some hosts fail or don't finish in time
+type DistributedDDL struct {
+	TaskTimeout int
+	OutputMode  string
+}
+
+// the distributed DDL errors refer to the task in the ZooKeeper queue, like
+// /clickhouse/task_queue/ddl/query-0000000042, or to the host that failed
+var (
+	distributedDDLEntryRegexp = regexp.MustCompile(`query-\d+`)
+	distributedDDLHostRegexp  = regexp.MustCompile(`There was an error on \[([^\]]+):(\d+)\]: (.*)`)
+)
+
+// DistributedDDLError reports the status of the hosts of a failed ON CLUSTER query
+type DistributedDDLError struct {
+	Entry string
+	Hosts []models.CHDistributedDDLHost
+	Err   error
+}
+
+func (e *DistributedDDLError) Error() string {
+	failed, unfinished := 0, 0
+	for _, host := range e.Hosts {
+		if host.Failed() {
+			failed++
+		} else if !host.Finished() {
+			unfinished++
+		}
+	}
+	task := "distributed DDL query"
+	if e.Entry != "" {
+		task = "distributed DDL task " + e.Entry
+	}
+	return fmt.Sprintf("%s failed on %d and is unfinished on %d of %d hosts: %v", task, failed, unfinished, len(e.Hosts), e.Err)
+}
+
+func (e *DistributedDDLError) Unwrap() error {
+	return e.Err
+}
+
+// executeDistributedDDL executes an ON CLUSTER query. When it fails, the status of each host is
+// read from system.distributed_ddl_queue, the queries keep running in background on the hosts
+// that didn't finish in time.
+func executeDistributedDDL(ctx context.Context, c *Client, query string) error {
+	switch c.DistributedDDL.OutputMode {
+	case DistributedDDLOutputModeNeverThrow, DistributedDDLOutputModeNullStatusOnTimeout, DistributedDDLOutputModeNullStatusOnTimeoutOnlyActive:
+		return executeDistributedDDLWithStatus(ctx, c, query)
+	}
+
+	err := c.Conn.Exec(ctx, query)
+	if err == nil {
+		return nil
+	}
+
+	ddlErr := &DistributedDDLError{Err: err}
+	if entry := distributedDDLEntryRegexp.FindString(err.Error()); entry != "" {
+		hosts, queueErr := c.getDistributedDDLHosts(ctx, entry)
+		if queueErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("reading distributed DDL task %s status: %v", entry, queueErr))
+		}
+		ddlErr.Entry = entry
+		ddlErr.Hosts = hosts
+	}
+	if len(ddlErr.Hosts) == 0 {
+		match := distributedDDLHostRegexp.FindStringSubmatch(err.Error())
+		if match == nil {
+			return fmt.Errorf("executing query: %w", err)
+		}
+		port, _ := strconv.Atoi(match[2])
+		ddlErr.Hosts = []models.CHDistributedDDLHost{{
+			Host:          match[1],
+			Port:          uint16(port),
+			Status:        "Finished",
+			ExceptionCode: 1,
+			ExceptionText: match[3],
+		}}
+	}
+	return ddlErr
+}
+
+// executeDistributedDDLWithStatus executes an ON CLUSTER query with an output mode returning
+// the status of each host instead of failing. Only the failures are reported as errors, the
+// hosts that didn't finish in time execute the query in background.
+func executeDistributedDDLWithStatus(ctx context.Context, c *Client, query string) error {
+	rows, err := c.Conn.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("executing query: %w", err)
+	}
+	defer rows.Close()
+
+	var hosts []models.CHDistributedDDLHost
+	failed := false
+	for rows.Next() {
+		values := make([]any, len(rows.ColumnTypes()))
+		for i, columnType := range rows.ColumnTypes() {
+			values[i] = reflect.New(columnType.ScanType()).Interface()
+		}
+		if err := rows.Scan(values...); err != nil {
+			return fmt.Errorf("scanning distributed DDL status: %w", err)
+		}
+
+		host := models.CHDistributedDDLHost{Status: "Finished"}
+		for i, column := range rows.Columns() {
+			value := reflect.Indirect(reflect.ValueOf(values[i]))
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					if column == "status" {
+						host.Status = "Unfinished"
+					}
+					continue
+				}
+				value = value.Elem()
+			}
+			switch column {
+			case "host":
+				host.Host = value.String()
+			case "port":
+				host.Port = uint16(reflectInteger(value))
+			case "status":
+				host.ExceptionCode = uint16(reflectInteger(value))
+			case "error":
+				host.ExceptionText = value.String()
+			}
+		}
+		if host.Failed() {
+			failed = true
+		} else if !host.Finished() {
+			tflog.Warn(ctx, fmt.Sprintf("Distributed DDL query unfinished on %s:%d, it will be executed in background", host.Host, host.Port))
+		}
+		hosts = append(hosts, host)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("executing query: %w", err)
+	}
+	if failed {
+		return &DistributedDDLError{Hosts: hosts, Err: errors.New("the query failed on some hosts")}
+	}
+	return nil
+}
+
+func reflectInteger(value reflect.Value) int64 {
+	if value.CanInt() {
+		return value.Int()
+	}
+	if value.CanUint() {
+		return int64(value.Uint())
+	}
+	return 0
+}
+
+func (c *Client) getDistributedDDLHosts(ctx context.Context, entry string) ([]models.CHDistributedDDLHost, error) {
+	query := fmt.Sprintf(
+		"SELECT ifNull(host, '') AS host, ifNull(port, 0) AS port, ifNull(toString(status), 'Unknown') AS status, ifNull(exception_code, 0) AS exception_code, ifNull(exception_text, '') AS exception_text FROM system.distributed_ddl_queue WHERE entry = '%s' ORDER BY host, port",
+		entry,
+	)
+	rows, err := c.Conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("reading distributed DDL queue from Clickhouse: %v", err)
+	}
+	defer rows.Close()
+
+	var hosts []models.CHDistributedDDLHost
+	for rows.Next() {
+		var host models.CHDistributedDDLHost
+		if err := rows.ScanStruct(&host); err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse distributed DDL queue row: %v", err)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, rows.Err()
+}
+
+func isDistributedDDL(query string) bool {
+	return common.FindTopLevelKeyword(query, "ON CLUSTER", 0) >= 0
+}
+
+// Diagnostics turns an error into diagnostics, with one diagnostic per failed or unfinished host
+// when an ON CLUSTER query failed, and one per failed or unfinished mutation
+func Diagnostics(err error) diag.Diagnostics {
+	var mutationErr *MutationError
+	if errors.As(err, &mutationErr) {
+		return mutationDiagnostics(err, mutationErr)
+	}
+	var ddlErr *DistributedDDLError
+	if !errors.As(err, &ddlErr) {
+		return diag.FromErr(err)
+	}
+
+	diags := diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+	}}
+	for _, host := range ddlErr.Hosts {
+		address := fmt.Sprintf("%s:%d", host.Host, host.Port)
+		switch {
+		case host.Failed():
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Distributed DDL query failed on %s", address),
+				Detail:   fmt.Sprintf("Code: %d. %s", host.ExceptionCode, host.ExceptionText),
+			})
+		case !host.Finished():
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Distributed DDL query unfinished on %s", address),
+				Detail:   fmt.Sprintf("The task status is %s, the host will execute it in background once it's active.", host.Status),
+			})
+		default:
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Distributed DDL query succeeded on %s", address),
+				Detail:   "The query is applied on this host, the resource may be partially created on the cluster.",
+			})
+		}
+	}
+	return diags
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+func (c *Client) GetFunction(ctx context.Context, name string) (*models.CHFunction, error) {
+	query := fmt.Sprintf("SELECT name, create_query FROM system.functions WHERE origin = 'SQLUserDefined' AND name = '%s'", name)
+	row := c.Conn.QueryRow(ctx, query)
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading function from Clickhouse: %v", row.Err())
+	}
+
+	var chFunction models.CHFunction
+	err := row.ScanStruct(&chFunction)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse function row: %v", err)
+	}
+	return &chFunction, nil
+}
+
+func (c *Client) CreateFunction(ctx context.Context, function models.FunctionResource) error {
+	if err := executeQuery(ctx, c, buildCreateFunctionSentence("CREATE", function)); err != nil {
+		return fmt.Errorf("creating Clickhouse function: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) ReplaceFunction(ctx context.Context, function models.FunctionResource) error {
+	if err := executeQuery(ctx, c, buildCreateFunctionSentence("CREATE OR REPLACE", function)); err != nil {
+		return fmt.Errorf("replacing Clickhouse function: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteFunction(ctx context.Context, function models.FunctionResource) error {
+	query := fmt.Sprintf("DROP FUNCTION IF EXISTS %s %s", function.Name, common.GetClusterStatement(function.Cluster))
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("deleting Clickhouse function: %w", err)
+	}
+	return nil
+}
+
+func buildCreateFunctionSentence(createStatement string, function models.FunctionResource) string {
+	return fmt.Sprintf(
+		"%s FUNCTION %s %s AS (%s) -> %s",
+		createStatement,
+		function.Name,
+		common.GetClusterStatement(function.Cluster),
+		strings.Join(function.Arguments, ", "),
+		function.Expression,
+	)
+}
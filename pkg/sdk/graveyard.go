@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// moveToGraveyard renames a table into the graveyard database instead of dropping it. The deletion
+// time and the cluster are kept in the table name so it can be purged on the hosts it was renamed
+// on once the retention expires.
+func (c *Client) moveToGraveyard(ctx context.Context, tableResource models.TableResource) error {
+	graveyard := c.DropPolicy.GraveyardDatabase
+	clusterStatement := common.GetClusterStatement(tableResource.Cluster)
+
+	err := executeQuery(ctx, c, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s %s", graveyard, clusterStatement))
+	if err != nil {
+		return fmt.Errorf("creating graveyard database: %w", err)
+	}
+
+	graveyardName := models.GraveyardTableName(tableResource.Database, tableResource.Name, time.Now().Unix(), tableResource.Cluster)
+	tflog.Info(ctx, fmt.Sprintf("Moving table %s.%s to %s.%s", tableResource.Database, tableResource.Name, graveyard, graveyardName))
+	err = executeQueryWithoutRetry(ctx, c, fmt.Sprintf(
+		"RENAME TABLE %s.%s TO %s.%s %s",
+		tableResource.Database, tableResource.Name, graveyard, graveyardName, clusterStatement), nil)
+	if err != nil {
+		return fmt.Errorf("moving table to graveyard: %v", err)
+	}
+
+	return c.purgeGraveyard(ctx)
+}
+
+// purgeGraveyard drops the tables kept in the graveyard database for longer than the retention,
+// on the cluster they were moved with
+func (c *Client) purgeGraveyard(ctx context.Context) error {
+	if c.DropPolicy.GraveyardRetention <= 0 {
+		return nil
+	}
+
+	tables, err := c.GetDBTables(ctx, c.DropPolicy.GraveyardDatabase)
+	if err != nil {
+		return fmt.Errorf("purging graveyard: %v", err)
+	}
+
+	for _, table := range tables {
+		deletedAt, cluster, ok := models.ParseGraveyardTableName(table.Name)
+		if !ok || time.Since(time.Unix(deletedAt, 0)) < c.DropPolicy.GraveyardRetention {
+			continue
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("Purging expired table %s.%s", table.Database, table.Name))
+		err = executeQuery(ctx, c, fmt.Sprintf("DROP TABLE IF EXISTS %s.%s %s SYNC", table.Database, table.Name, common.GetClusterStatement(cluster)))
+		if err != nil {
+			return fmt.Errorf("purging graveyard table %s: %w", table.Name, err)
+		}
+	}
+	return nil
+}
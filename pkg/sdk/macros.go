@@ -0,0 +1,26 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetMacros returns the macros of the connected node, substituted in the ZooKeeper paths and
+// replica names of the Replicated tables
+func (c *Client) GetMacros(ctx context.Context) (map[string]string, error) {
+	rows, err := c.Conn.Query(ctx, "SELECT macro, substitution FROM system.macros")
+	if err != nil {
+		return nil, fmt.Errorf("reading macros from Clickhouse: %v", err)
+	}
+	defer rows.Close()
+
+	macros := make(map[string]string)
+	for rows.Next() {
+		var macro, substitution string
+		if err := rows.Scan(&macro, &substitution); err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse macro row: %v", err)
+		}
+		macros[macro] = substitution
+	}
+	return macros, rows.Err()
+}
@@ -0,0 +1,241 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const mutationPollInterval = 2 * time.Second
+
+// number of consecutive polls a mutation must fail with the same reason to be considered failed
+const mutationFailedPolls = 3
+
+// MutationPolicy defines how the ALTER queries spawning mutations, like column type changes or
+// column drops, wait for them
+type MutationPolicy struct {
+	Wait          bool
+	KillOnFailure bool
+}
+
+// MutationError reports the mutations of a table that failed, or didn't finish in time when Err
+// is set
+type MutationError struct {
+	Database  string
+	Table     string
+	Mutations []models.CHMutation
+	Killed    bool
+	Err       error
+}
+
+func (e *MutationError) Error() string {
+	ids := make([]string, 0, len(e.Mutations))
+	for _, mutation := range e.Mutations {
+		ids = append(ids, mutation.MutationID)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("waiting for mutations %s of table %s.%s: %v", strings.Join(ids, ", "), e.Database, e.Table, e.Err)
+	}
+	return fmt.Sprintf("mutations %s of table %s.%s failed", strings.Join(ids, ", "), e.Database, e.Table)
+}
+
+func (e *MutationError) Unwrap() error {
+	return e.Err
+}
+
+func mutationDiagnostics(err error, mutationErr *MutationError) diag.Diagnostics {
+	diags := diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+	}}
+	for _, mutation := range mutationErr.Mutations {
+		table := fmt.Sprintf("%s.%s", mutationErr.Database, mutationErr.Table)
+		switch {
+		case mutationErr.Err == nil && mutationErr.Killed:
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Mutation %s of %s failed and was killed", mutation.MutationID, table),
+				Detail:   fmt.Sprintf("%s: %s", mutation.Command, mutation.LatestFailReason),
+			})
+		case mutationErr.Err == nil:
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Mutation %s of %s failed", mutation.MutationID, table),
+				Detail: fmt.Sprintf("%s: %s\nThe mutation is retried in background, it can be stopped with KILL MUTATION WHERE database = '%s' AND table = '%s' AND mutation_id = '%s'.",
+					mutation.Command, mutation.LatestFailReason, mutationErr.Database, mutationErr.Table, mutation.MutationID),
+			})
+		default:
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Mutation %s of %s unfinished", mutation.MutationID, table),
+				Detail:   fmt.Sprintf("%s: %d parts to do, the mutation keeps running in background.", mutation.Command, mutation.PartsToDo),
+			})
+		}
+	}
+	return diags
+}
+
+// executeMutation executes an ALTER query and waits until the mutations it spawned are done. They
+// are told apart from the previous mutations of the table by their id and their command, only the
+// mutations of the connected node are tracked. When Clickhouse formats the command in a way that
+// doesn't match, all the new mutations are waited for.
+func executeMutation(ctx context.Context, c *Client, table models.TableResource, query string) error {
+	if !c.MutationPolicy.Wait {
+		return executeQuery(ctx, c, query)
+	}
+
+	previous, err := c.getMutations(ctx, table.Database, table.Name, nil)
+	if err != nil {
+		return err
+	}
+	previousIDs := make(map[string]bool, len(previous))
+	for _, mutation := range previous {
+		previousIDs[mutation.MutationID] = true
+	}
+
+	if err := executeQuery(ctx, c, query); err != nil {
+		return err
+	}
+
+	mutations, err := c.getMutations(ctx, table.Database, table.Name, nil)
+	if err != nil {
+		return err
+	}
+	command := mutationCommand(query)
+	var ids, matchingIDs []string
+	for _, mutation := range mutations {
+		if previousIDs[mutation.MutationID] {
+			continue
+		}
+		ids = append(ids, mutation.MutationID)
+		if mutation.MatchesCommand(command) {
+			matchingIDs = append(matchingIDs, mutation.MutationID)
+		}
+	}
+	if len(matchingIDs) > 0 {
+		ids = matchingIDs
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.waitForMutations(ctx, table, ids)
+}
+
+// mutationCommand returns the commands of an ALTER query, following the table name and the
+// cluster statement
+func mutationCommand(query string) string {
+	query = strings.TrimSpace(query)
+	if len(query) < len("ALTER TABLE") || !strings.EqualFold(query[:len("ALTER TABLE")], "ALTER TABLE") {
+		return query
+	}
+	rest := skipQueryToken(strings.TrimSpace(query[len("ALTER TABLE"):]))
+	if len(rest) >= len("ON CLUSTER") && strings.EqualFold(rest[:len("ON CLUSTER")], "ON CLUSTER") {
+		rest = skipQueryToken(strings.TrimSpace(rest[len("ON CLUSTER"):]))
+	}
+	return rest
+}
+
+func skipQueryToken(query string) string {
+	if i := strings.IndexFunc(query, unicode.IsSpace); i >= 0 {
+		return strings.TrimSpace(query[i:])
+	}
+	return ""
+}
+
+// waitForMutations polls the mutations until they are done. A mutation failing with the same
+// reason for mutationFailedPolls consecutive polls is considered failed, as Clickhouse keeps
+// retrying it.
+func (c *Client) waitForMutations(ctx context.Context, table models.TableResource, ids []string) error {
+	failures := models.NewMutationFailures(mutationFailedPolls)
+	for {
+		mutations, err := c.getMutations(ctx, table.Database, table.Name, ids)
+		if err != nil {
+			return err
+		}
+
+		var failed, running []models.CHMutation
+		for _, mutation := range mutations {
+			if failures.Failed(mutation) {
+				failed = append(failed, mutation)
+			} else if !mutation.Done() {
+				running = append(running, mutation)
+			}
+		}
+		if len(failed) > 0 {
+			mutationErr := &MutationError{Database: table.Database, Table: table.Name, Mutations: failed}
+			if c.MutationPolicy.KillOnFailure {
+				if err := c.killMutations(ctx, table, failed); err != nil {
+					return fmt.Errorf("%v, killing them: %w", mutationErr, err)
+				}
+				mutationErr.Killed = true
+			}
+			return mutationErr
+		}
+		if len(running) == 0 {
+			return nil
+		}
+
+		for _, mutation := range running {
+			tflog.Debug(ctx, fmt.Sprintf("Waiting for mutation %s of table %s.%s, %d parts to do", mutation.MutationID, table.Database, table.Name, mutation.PartsToDo))
+		}
+		select {
+		case <-ctx.Done():
+			return &MutationError{Database: table.Database, Table: table.Name, Mutations: running, Err: ctx.Err()}
+		case <-time.After(mutationPollInterval):
+		}
+	}
+}
+
+// getMutations reads the mutations of a table, all of them when no id is given
+func (c *Client) getMutations(ctx context.Context, database string, table string, ids []string) ([]models.CHMutation, error) {
+	query := fmt.Sprintf(
+		"SELECT mutation_id, command, is_done, parts_to_do, latest_fail_reason FROM system.mutations WHERE database = '%s' AND table = '%s'",
+		database, table,
+	)
+	if len(ids) > 0 {
+		query += fmt.Sprintf(" AND mutation_id IN (%s)", quoteMutationIDs(ids))
+	}
+
+	rows, err := c.Conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("reading mutations of table %s.%s: %v", database, table, err)
+	}
+	defer rows.Close()
+
+	var mutations []models.CHMutation
+	for rows.Next() {
+		var mutation models.CHMutation
+		if err := rows.ScanStruct(&mutation); err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse mutation row: %v", err)
+		}
+		mutations = append(mutations, mutation)
+	}
+	return mutations, rows.Err()
+}
+
+func (c *Client) killMutations(ctx context.Context, table models.TableResource, mutations []models.CHMutation) error {
+	ids := make([]string, 0, len(mutations))
+	for _, mutation := range mutations {
+		ids = append(ids, mutation.MutationID)
+	}
+	query := fmt.Sprintf(
+		"KILL MUTATION %s WHERE database = '%s' AND table = '%s' AND mutation_id IN (%s)",
+		common.GetClusterStatement(table.Cluster), table.Database, table.Name, quoteMutationIDs(ids),
+	)
+	return executeQuery(ctx, c, query)
+}
+
+func quoteMutationIDs(ids []string) string {
+	quoted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		quoted = append(quoted, "'"+id+"'")
+	}
+	return strings.Join(quoted, ", ")
+}
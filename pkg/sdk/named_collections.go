@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+// GetNamedCollection returns the keys of a named collection, the values are masked by Clickhouse
+func (c *Client) GetNamedCollection(ctx context.Context, name string) (*models.CHNamedCollection, error) {
+	query := fmt.Sprintf("SELECT name, mapKeys(collection) AS keys FROM system.named_collections WHERE name = '%s'", name)
+	row := c.Conn.QueryRow(ctx, query)
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading named collection from Clickhouse: %v", row.Err())
+	}
+
+	var chNamedCollection models.CHNamedCollection
+	err := row.ScanStruct(&chNamedCollection)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse named collection row: %v", err)
+	}
+	return &chNamedCollection, nil
+}
+
+func (c *Client) CreateNamedCollection(ctx context.Context, collection models.NamedCollectionResource) error {
+	query := fmt.Sprintf(
+		"CREATE NAMED COLLECTION %s %s AS %s",
+		collection.Name,
+		common.GetClusterStatement(collection.Cluster),
+		buildNamedCollectionParams(collection.Params),
+	)
+	// the query isn't part of the error as it holds the values of the collection
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("creating Clickhouse named collection: %w", err)
+	}
+	return nil
+}
+
+// AlterNamedCollection sets and deletes keys of a named collection in a single statement
+func (c *Client) AlterNamedCollection(ctx context.Context, collection models.NamedCollectionResource, setParams []models.NamedCollectionParam, deleteKeys []string) error {
+	if len(setParams) == 0 && len(deleteKeys) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER NAMED COLLECTION %s %s", collection.Name, common.GetClusterStatement(collection.Cluster))
+	if len(setParams) > 0 {
+		query += " SET " + buildNamedCollectionParams(setParams)
+	}
+	if len(deleteKeys) > 0 {
+		query += " DELETE " + strings.Join(deleteKeys, ", ")
+	}
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("altering Clickhouse named collection: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteNamedCollection(ctx context.Context, collection models.NamedCollectionResource) error {
+	query := fmt.Sprintf("DROP NAMED COLLECTION IF EXISTS %s %s", collection.Name, common.GetClusterStatement(collection.Cluster))
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("deleting Clickhouse named collection: %w", err)
+	}
+	return nil
+}
+
+func buildNamedCollectionParams(params []models.NamedCollectionParam) string {
+	definitions := make([]string, 0, len(params))
+	for _, param := range params {
+		overridable := "NOT OVERRIDABLE"
+		if param.Overridable {
+			overridable = "OVERRIDABLE"
+		}
+		value := strings.ReplaceAll(strings.ReplaceAll(param.Value, "\\", "\\\\"), "'", "\\'")
+		definitions = append(definitions, fmt.Sprintf("%s = '%s' %s", param.Key, value, overridable))
+	}
+	return strings.Join(definitions, ", ")
+}
@@ -0,0 +1,35 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+type querySettingsKey struct{}
+
+// WithQuerySettings returns a context applying the settings to the queries executed with it, on top
+// of the provider settings. It allows resources to enable the experimental features their DDL
+// requires without enabling them for every query.
+func WithQuerySettings(ctx context.Context, settings map[string]string) context.Context {
+	return context.WithValue(ctx, querySettingsKey{}, settings)
+}
+
+// queryContext returns the context of a query with the settings of the context along with the given
+// settings and options. clickhouse.Context inherits the options of the parent context but replaces
+// its settings map, so the settings are merged before being set.
+func queryContext(ctx context.Context, settings clickhouse.Settings, options ...clickhouse.QueryOption) context.Context {
+	querySettings, _ := ctx.Value(querySettingsKey{}).(map[string]string)
+	if len(querySettings) == 0 && len(settings) == 0 && len(options) == 0 {
+		return ctx
+	}
+
+	merged := make(clickhouse.Settings, len(querySettings)+len(settings))
+	for key, value := range querySettings {
+		merged[key] = value
+	}
+	for key, value := range settings {
+		merged[key] = value
+	}
+	return clickhouse.Context(ctx, append([]clickhouse.QueryOption{clickhouse.WithSettings(merged)}, options...)...)
+}
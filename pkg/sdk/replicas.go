@@ -0,0 +1,220 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+)
+
+// clusterFunctionArgument returns the cluster argument of the clusterAllReplicas table function,
+// the cluster names holding macros are already quoted
+func clusterFunctionArgument(cluster string) string {
+	if strings.HasPrefix(cluster, "'") {
+		return cluster
+	}
+	return fmt.Sprintf("'%s'", cluster)
+}
+
+// hostDefinitions reads a definition of a resource on each host of the cluster, as one string per
+// host. The hosts missing the resource are mapped to an empty definition.
+func (c *Client) hostDefinitions(ctx context.Context, cluster string, query string) (string, map[string]string, error) {
+	ctx = queryContext(ctx, nil)
+	var connectedHost string
+	if err := c.Conn.QueryRow(ctx, "SELECT hostName()").Scan(&connectedHost); err != nil {
+		return "", nil, fmt.Errorf("reading Clickhouse host name: %v", err)
+	}
+
+	definitions := make(map[string]string)
+	hostsQuery := fmt.Sprintf("SELECT hostName() AS host FROM clusterAllReplicas(%s, system.one)", clusterFunctionArgument(cluster))
+	rows, err := c.Conn.Query(ctx, hostsQuery)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading hosts of cluster %s: %v", cluster, err)
+	}
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			rows.Close()
+			return "", nil, fmt.Errorf("scanning Clickhouse host row: %v", err)
+		}
+		definitions[host] = ""
+	}
+	rows.Close()
+
+	rows, err = c.Conn.Query(ctx, query)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading definitions on cluster %s: %v", cluster, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var host, definition string
+		if err := rows.Scan(&host, &definition); err != nil {
+			return "", nil, fmt.Errorf("scanning Clickhouse definition row: %v", err)
+		}
+		definitions[host] += definition + "\n"
+	}
+	return connectedHost, definitions, rows.Err()
+}
+
+func getDivergences(connectedHost string, definitions map[string]string, resource string) []models.HostDivergence {
+	var divergences []models.HostDivergence
+	for host, definition := range definitions {
+		switch {
+		case host == connectedHost:
+		case definition == "":
+			divergences = append(divergences, models.HostDivergence{Host: host, Reason: resource + " is missing"})
+		case definition != definitions[connectedHost]:
+			divergences = append(divergences, models.HostDivergence{Host: host, Reason: resource + " definition differs from " + connectedHost})
+		}
+	}
+	sort.Slice(divergences, func(i, j int) bool { return divergences[i].Host < divergences[j].Host })
+	return divergences
+}
+
+// GetTableDivergences compares the engine and the columns of a table on all the replicas of the
+// cluster with the connected node
+func (c *Client) GetTableDivergences(ctx context.Context, cluster string, database string, table string) ([]models.HostDivergence, error) {
+	query := fmt.Sprintf(
+		"SELECT host, definition FROM ("+
+			"SELECT hostName() AS host, 0 AS position, engine_full AS definition FROM clusterAllReplicas(%[1]s, system.tables) WHERE database = '%[2]s' AND name = '%[3]s' "+
+			"UNION ALL "+
+			"SELECT hostName() AS host, position, concat(name, ' ', type) AS definition FROM clusterAllReplicas(%[1]s, system.columns) WHERE database = '%[2]s' AND table = '%[3]s'"+
+			") ORDER BY host, position",
+		clusterFunctionArgument(cluster), database, table,
+	)
+	connectedHost, definitions, err := c.hostDefinitions(ctx, cluster, query)
+	if err != nil {
+		return nil, err
+	}
+	return getDivergences(connectedHost, definitions, "table"), nil
+}
+
+// GetUserDivergences compares the default roles of a user on all the replicas of the cluster
+// with the connected node
+func (c *Client) GetUserDivergences(ctx context.Context, cluster string, name string) ([]models.HostDivergence, error) {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, arrayStringConcat(arraySort(default_roles_list), ',') AS definition FROM clusterAllReplicas(%s, system.users) WHERE name = '%s'",
+		clusterFunctionArgument(cluster), name,
+	)
+	connectedHost, definitions, err := c.hostDefinitions(ctx, cluster, query)
+	if err != nil {
+		return nil, err
+	}
+	return getDivergences(connectedHost, definitions, "user"), nil
+}
+
+// RepairTable creates the table on the replicas missing it, then adds the columns missing on some
+// replicas and modifies the columns whose definition differs between replicas
+func (c *Client) RepairTable(ctx context.Context, table models.TableResource) error {
+	if err := executeQuery(ctx, c, buildCreateTableSentence(table, "CREATE TABLE IF NOT EXISTS")); err != nil {
+		return fmt.Errorf("creating table %s.%s on the missing replicas: %w", table.Database, table.Name, err)
+	}
+	if len(table.Columns) == 0 {
+		return nil
+	}
+
+	hosts, replicaColumns, err := c.getReplicaColumns(ctx, table.Cluster, table.Database, table.Name)
+	if err != nil {
+		return err
+	}
+
+	var clauses []string
+	for _, column := range table.GetColumnsResourceList() {
+		missing, differs := replicaColumnStatus(column.Name, hosts, replicaColumns)
+		if missing {
+			clauses = append(clauses, "ADD COLUMN IF NOT EXISTS "+buildColumnDefinition(column))
+		}
+		if differs {
+			clauses = append(clauses, "MODIFY COLUMN "+buildColumnDefinition(column))
+		}
+	}
+	if len(clauses) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf("ALTER TABLE %s.%s %s %s", table.Database, table.Name, common.GetClusterStatement(table.Cluster), strings.Join(clauses, ", "))
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("repairing columns of table %s.%s: %w", table.Database, table.Name, err)
+	}
+	return nil
+}
+
+// replicaColumn counts the replicas holding a column and its distinct definitions across them
+type replicaColumn struct {
+	Hosts       uint64
+	Definitions uint64
+}
+
+// getReplicaColumns returns the number of hosts of the cluster and the replicas of each column of
+// the table
+func (c *Client) getReplicaColumns(ctx context.Context, cluster string, database string, table string) (uint64, map[string]replicaColumn, error) {
+	ctx = queryContext(ctx, nil)
+	var hosts uint64
+	hostsQuery := fmt.Sprintf("SELECT count() FROM clusterAllReplicas(%s, system.one)", clusterFunctionArgument(cluster))
+	if err := c.Conn.QueryRow(ctx, hostsQuery).Scan(&hosts); err != nil {
+		return 0, nil, fmt.Errorf("reading hosts of cluster %s: %v", cluster, err)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT name, uniqExact(hostName()) AS hosts, uniqExact(type, default_kind, default_expression, compression_codec, comment) AS definitions "+
+			"FROM clusterAllReplicas(%s, system.columns) WHERE database = '%s' AND table = '%s' GROUP BY name",
+		clusterFunctionArgument(cluster), database, table,
+	)
+	rows, err := c.Conn.Query(ctx, query)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading columns of table %s.%s on cluster %s: %v", database, table, cluster, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]replicaColumn)
+	for rows.Next() {
+		var name string
+		var column replicaColumn
+		if err := rows.Scan(&name, &column.Hosts, &column.Definitions); err != nil {
+			return 0, nil, fmt.Errorf("scanning Clickhouse column row: %v", err)
+		}
+		columns[name] = column
+	}
+	return hosts, columns, rows.Err()
+}
+
+// replicaColumnStatus returns whether a column is missing on some replicas and whether its
+// definition differs between them. Nested columns are reported by Clickhouse as one column per
+// field, so their fields are checked instead.
+func replicaColumnStatus(name string, hosts uint64, columns map[string]replicaColumn) (missing bool, differs bool) {
+	found := false
+	for columnName, column := range columns {
+		if columnName != name && !strings.HasPrefix(columnName, name+".") {
+			continue
+		}
+		found = true
+		missing = missing || column.Hosts < hosts
+		differs = differs || column.Definitions > 1
+	}
+	return missing || !found, differs
+}
+
+// RepairUser creates the user on the replicas missing it and sets its roles again
+func (c *Client) RepairUser(ctx context.Context, user models.UserResource) error {
+	clusterStatement := common.GetClusterStatement(user.Cluster)
+	query := fmt.Sprintf("CREATE USER IF NOT EXISTS %s %s IDENTIFIED WITH sha256_password BY '%s'", user.Name, clusterStatement, user.Password)
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("creating user %s on the missing replicas: %w", user.Name, err)
+	}
+
+	roles := common.StringSetToList(user.Roles)
+	if len(roles) == 0 {
+		return nil
+	}
+	query = fmt.Sprintf("GRANT %s %s TO %s", clusterStatement, strings.Join(roles, ","), user.Name)
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("granting roles to user %s: %w", user.Name, err)
+	}
+	query = fmt.Sprintf("ALTER USER %s %s DEFAULT ROLE %s", user.Name, clusterStatement, strings.Join(roles, ","))
+	if err := executeQuery(ctx, c, query); err != nil {
+		return fmt.Errorf("setting default roles of user %s: %w", user.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// RetryPolicy defines how the queries failing with a transient error are retried, waiting an
+// exponential backoff between the attempts
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// the Clickhouse exception codes of the errors that may succeed when the query is executed again
+var retryableExceptionCodes = map[int32]string{
+	202: "TOO_MANY_SIMULTANEOUS_QUERIES",
+	203: "NO_FREE_CONNECTION",
+	209: "SOCKET_TIMEOUT",
+	210: "NETWORK_ERROR",
+	225: "NO_ZOOKEEPER",
+	242: "TABLE_IS_READ_ONLY",
+	285: "TOO_FEW_LIVE_REPLICAS",
+	473: "DEADLOCK_AVOIDED",
+	517: "CANNOT_ASSIGN_ALTER",
+	999: "KEEPER_EXCEPTION",
+}
+
+// isRetryable tells whether an error is transient. The ON CLUSTER queries that failed on some
+// hosts are not retried, as they already ran on the others. The network errors and timeouts leave
+// unknown whether the query ran, so they are only retried for the idempotent queries.
+func isRetryable(err error, idempotent bool) bool {
+	var ddlErr *DistributedDDLError
+	if errors.As(err, &ddlErr) && len(ddlErr.Hosts) > 0 {
+		return false
+	}
+
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		_, ok := retryableExceptionCodes[exception.Code]
+		return ok
+	}
+
+	var netErr net.Error
+	return idempotent && (errors.As(err, &netErr) && netErr.Timeout() ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE))
+}
+
+// isIdempotentQuery tells whether a query can be executed again without effect once it succeeded,
+// i.e. it is guarded by IF EXISTS or IF NOT EXISTS
+func isIdempotentQuery(query string) bool {
+	return common.FindTopLevelKeyword(query, "IF NOT EXISTS", 0) >= 0 || common.FindTopLevelKeyword(query, "IF EXISTS", 0) >= 0
+}
+
+// retry executes fn until it succeeds, fails with an error that isn't transient, runs out of
+// attempts or the context is done
+func (c *Client) retry(ctx context.Context, description string, idempotent bool, fn func() error) error {
+	maxAttempts := max(c.RetryPolicy.MaxAttempts, 1)
+	backoff := c.RetryPolicy.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= maxAttempts || !isRetryable(err, idempotent) {
+			return err
+		}
+
+		tflog.Warn(ctx, fmt.Sprintf("%s failed with a transient error, retrying in %s (attempt %d of %d): %v", description, backoff, attempt, maxAttempts, err))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (retry interrupted: %v)", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.RetryPolicy.MaxBackoff)
+	}
+}
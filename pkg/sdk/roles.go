@@ -63,6 +63,8 @@ func (c *Client) GetRole(ctx context.Context, roleName string) (*models.CHRole,
 }
 
 func (c *Client) UpdateRole(ctx context.Context, rolePlan models.RoleResource, resourceData *schema.ResourceData) (*models.CHRole, error) {
+	// the role queries are executed on the connection, the query settings are applied to the context
+	ctx = queryContext(ctx, nil)
 	stateRoleName, _ := resourceData.GetChange("name")
 	chRole, err := c.GetRole(ctx, stateRoleName.(string))
 
@@ -140,6 +142,7 @@ func (c *Client) UpdateRole(ctx context.Context, rolePlan models.RoleResource, r
 }
 
 func (c *Client) CreateRole(ctx context.Context, name string, database string, privileges []string) (*models.CHRole, error) {
+	ctx = queryContext(ctx, nil)
 	err := c.Conn.Exec(ctx, fmt.Sprintf("CREATE ROLE %s", name))
 	if err != nil {
 		return nil, fmt.Errorf("error creating role: %s", err)
@@ -163,5 +166,5 @@ func (c *Client) CreateRole(ctx context.Context, name string, database string, p
 }
 
 func (c *Client) DeleteRole(ctx context.Context, name string) error {
-	return c.Conn.Exec(ctx, fmt.Sprintf("DROP ROLE %s", name))
+	return c.Conn.Exec(queryContext(ctx, nil), fmt.Sprintf("DROP ROLE %s", name))
 }
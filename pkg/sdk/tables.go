@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -31,6 +35,18 @@ func (c *Client) UpdateTable(ctx context.Context, table models.TableResource, re
 		}
 	}
 
+	// columns appended to the sorting key must be added in the same query modifying it
+	var sortingKeyColumns []string
+	if resourceData.HasChange("order_by") {
+		oldOrderBy, _ := resourceData.GetChange("order_by")
+		appended, ok := models.GetOrderByExtension(common.MapArrayInterfaceToArrayOfStrings(oldOrderBy.([]interface{})), table.OrderBy)
+		if !ok {
+			return fmt.Errorf("sorting key of table %s.%s can only be extended with new columns", table.Database, table.Name)
+		}
+		sortingKeyColumns = appended
+	}
+
+	var sortingKeyClauses []string
 	if resourceData.HasChange("column") {
 		old, new := resourceData.GetChange("column")
 		oldColumns := old.([]interface{})
@@ -46,9 +62,15 @@ func (c *Client) UpdateTable(ctx context.Context, table models.TableResource, re
 
 			columnName := columnMap["name"].(string)
 
-			err := UpdateColumns(ctx, c, table, clusterStatement, columnMap, oldColumnsMap)
-			if err != nil {
-				return err
+			// all the new columns are added along with the sorting key columns, as they may be
+			// located after one of them
+			if _, exists := oldColumnsMap[columnName]; !exists && len(sortingKeyColumns) > 0 {
+				sortingKeyClauses = append(sortingKeyClauses, buildAddColumnClause(columnMap))
+			} else {
+				err := UpdateColumns(ctx, c, table, clusterStatement, columnMap, oldColumnsMap)
+				if err != nil {
+					return err
+				}
 			}
 
 			location = "AFTER " + columnName
@@ -59,11 +81,60 @@ func (c *Client) UpdateTable(ctx context.Context, table models.TableResource, re
 			return err
 		}
 	}
+
+	if resourceData.HasChange("order_by") {
+		sortingKeyClauses = append(sortingKeyClauses, fmt.Sprintf("MODIFY ORDER BY (%s)", strings.Join(table.OrderBy, ", ")))
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s %s", table.Database, table.Name, clusterStatement, strings.Join(sortingKeyClauses, ", "))
+		err := executeQueryWithoutRetry(ctx, c, query, nil)
+		if err != nil {
+			return fmt.Errorf("modifying sorting key: %w", err)
+		}
+	}
+
+	if resourceData.HasChange("sample_by") {
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s REMOVE SAMPLE BY", table.Database, table.Name, clusterStatement)
+		if table.SampleBy != "" {
+			query = fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY SAMPLE BY %s", table.Database, table.Name, clusterStatement, table.SampleBy)
+		}
+		err := executeQuery(ctx, c, query)
+		if err != nil {
+			return fmt.Errorf("modifying sampling key: %w", err)
+		}
+	}
+	return nil
+}
+
+// ModifyTableSettings changes the engine settings of a table in place, the reset ones go back to
+// their default value
+func (c *Client) ModifyTableSettings(ctx context.Context, table models.TableResource, settings map[string]string, resetSettings []string) error {
+	clusterStatement := common.GetClusterStatement(table.Cluster)
+
+	if len(settings) > 0 {
+		keys := make([]string, 0, len(settings))
+		for key := range settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		assignments := make([]string, 0, len(keys))
+		for _, key := range keys {
+			assignments = append(assignments, buildSettingAssignment(key, settings[key]))
+		}
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY SETTING %s", table.Database, table.Name, clusterStatement, strings.Join(assignments, ", "))
+		if err := executeQuery(ctx, c, query); err != nil {
+			return fmt.Errorf("modifying settings of table %s.%s: %w", table.Database, table.Name, err)
+		}
+	}
+	if len(resetSettings) > 0 {
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s RESET SETTING %s", table.Database, table.Name, clusterStatement, strings.Join(resetSettings, ", "))
+		if err := executeQuery(ctx, c, query); err != nil {
+			return fmt.Errorf("resetting settings of table %s.%s: %w", table.Database, table.Name, err)
+		}
+	}
 	return nil
 }
 
 func (c *Client) GetTable(ctx context.Context, database string, table string) (*models.CHTable, error) {
-	query := fmt.Sprintf("SELECT database, name, engine_full, engine, sorting_key, comment FROM system.tables where database = '%s' and name = '%s'", database, table)
+	query := fmt.Sprintf("SELECT database, name, toString(uuid) AS uuid, engine_full, engine, sorting_key, primary_key, sampling_key, comment FROM system.tables where database = '%s' and name = '%s'", database, table)
 	row := c.Conn.QueryRow(ctx, query)
 
 	if row.Err() != nil {
@@ -97,7 +168,72 @@ func (c *Client) CreateTable(ctx context.Context, tableResource models.TableReso
 	return executeQuery(ctx, c, query)
 }
 
+// DeleteTable removes a table according to the provider drop policy: dropping it, detaching
+// it permanently or moving it into the graveyard database
 func (c *Client) DeleteTable(ctx context.Context, tableResource models.TableResource) error {
-	query := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s %s", tableResource.Database, tableResource.Name, common.GetClusterStatement(tableResource.Cluster))
+	if !c.DropPolicy.AllowDrop {
+		return fmt.Errorf("table %s.%s can't be deleted, dropping tables is disabled by the provider allow_drop setting", tableResource.Database, tableResource.Name)
+	}
+
+	switch c.DropPolicy.Mode {
+	case DropModeDetach:
+		return c.detachTable(ctx, tableResource)
+	case DropModeGraveyard:
+		return c.moveToGraveyard(ctx, tableResource)
+	default:
+		query := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s %s", tableResource.Database, tableResource.Name, common.GetClusterStatement(tableResource.Cluster))
+		return executeQuery(ctx, c, query)
+	}
+}
+
+// detachTable detaches a table permanently under a name suffixed with the deletion time, as the
+// detached metadata would prevent a table from being created again under the same name
+func (c *Client) detachTable(ctx context.Context, tableResource models.TableResource) error {
+	clusterStatement := common.GetClusterStatement(tableResource.Cluster)
+	exists, err := c.tableExists(ctx, tableResource.Database, tableResource.Name)
+	if err != nil || !exists {
+		return err
+	}
+
+	detachedName := fmt.Sprintf("%s__detached__%d", tableResource.Name, time.Now().Unix())
+	tflog.Info(ctx, fmt.Sprintf("Detaching table %s.%s as %s.%s", tableResource.Database, tableResource.Name, tableResource.Database, detachedName))
+	query := fmt.Sprintf("RENAME TABLE %s.%s TO %s.%s %s", tableResource.Database, tableResource.Name, tableResource.Database, detachedName, clusterStatement)
+	if err := executeQueryWithoutRetry(ctx, c, query, nil); err != nil {
+		return fmt.Errorf("renaming table before detaching it: %w", err)
+	}
+	query = fmt.Sprintf("DETACH TABLE %s.%s %s PERMANENTLY", tableResource.Database, detachedName, clusterStatement)
 	return executeQuery(ctx, c, query)
 }
+
+// RenameTable moves a table to a new name, in the same or in another database
+func (c *Client) RenameTable(ctx context.Context, cluster string, oldDatabase string, oldName string, newDatabase string, newName string) error {
+	// when the database was renamed in place, the table has already been moved along with it
+	exists, err := c.tableExists(ctx, oldDatabase, oldName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		movedExists, err := c.tableExists(ctx, newDatabase, newName)
+		if err != nil {
+			return err
+		}
+		if movedExists {
+			return nil
+		}
+	}
+
+	query := fmt.Sprintf("RENAME TABLE %s.%s TO %s.%s %s", oldDatabase, oldName, newDatabase, newName, common.GetClusterStatement(cluster))
+	if err := executeQueryWithoutRetry(ctx, c, query, nil); err != nil {
+		return fmt.Errorf("renaming table %s.%s: %w", oldDatabase, oldName, err)
+	}
+	return nil
+}
+
+func (c *Client) tableExists(ctx context.Context, database string, table string) (bool, error) {
+	var count uint64
+	query := fmt.Sprintf("SELECT count() FROM system.tables WHERE database = '%s' AND name = '%s'", database, table)
+	if err := c.Conn.QueryRow(ctx, query).Scan(&count); err != nil {
+		return false, fmt.Errorf("checking if table %s.%s exists: %v", database, table, err)
+	}
+	return count > 0, nil
+}
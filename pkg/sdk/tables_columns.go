@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -58,9 +59,26 @@ func copyToMap(iface interface{}) map[string]interface{} {
 }
 
 func executeQuery(ctx context.Context, c *Client, query string) error {
+	return executeQueryWithOptions(ctx, c, query, nil)
+}
+
+// executeQueryWithOptions executes a query with settings and options overriding those of the context
+func executeQueryWithOptions(ctx context.Context, c *Client, query string, settings clickhouse.Settings, options ...clickhouse.QueryOption) error {
+	return c.retry(ctx, "query", isIdempotentQuery(query), func() error {
+		return executeQueryWithoutRetry(ctx, c, query, settings, options...)
+	})
+}
+
+// executeQueryWithoutRetry executes a query once, for the queries that can't be executed again
+// after a failure as they may have been partially applied, e.g. a RENAME or an INSERT SELECT
+func executeQueryWithoutRetry(ctx context.Context, c *Client, query string, settings clickhouse.Settings, options ...clickhouse.QueryOption) error {
+	ctx = queryContext(ctx, settings, options...)
+	if isDistributedDDL(query) {
+		return executeDistributedDDL(ctx, c, query)
+	}
 	err := c.Conn.Exec(ctx, query)
 	if err != nil {
-		return fmt.Errorf("executing query: %v", err)
+		return fmt.Errorf("executing query: %w", err)
 	}
 	return nil
 }
@@ -83,20 +101,30 @@ func UpdateColumns(ctx context.Context, c *Client, table models.TableResource, c
 		return append([]interface{}{table.Database, table.Name, clusterStatement, columnName}, extraArgs...)
 	}
 
+	_, isNested := models.GetNestedFields(columnMap["type"].(string))
+	if exists && isNested {
+		if err := updateNestedColumn(ctx, c, table, clusterStatement, columnName, oldColumnMap["type"].(string), columnMap["type"].(string)); err != nil {
+			return err
+		}
+	}
+
 	changes := []struct {
 		condition bool
 		query     string
 		args      []interface{}
+		mutation  bool
 	}{
 		{
 			condition: !exists,
-			query:     "ALTER TABLE %s.%s %s ADD COLUMN %s %s %s %s %s %s %s",
-			args:      generateArgs(columnMap["type"], columnMap["default_kind"], columnMap["default_expression"], columnMap["compression_codec"], columnMap["comment"].(string), columnMap["location"]),
+			query:     "ALTER TABLE %s.%s %s %s",
+			args:      []interface{}{table.Database, table.Name, clusterStatement, buildAddColumnClause(columnMap)},
 		},
 		{
-			condition: exists && columnDiffers(oldColumnMap, columnMap, "type"),
+			condition: exists && !isNested && !models.ColumnTypesEqual(oldColumnMap["type"].(string), columnMap["type"].(string)),
 			query:     "ALTER TABLE %s.%s %s MODIFY COLUMN %s %s",
 			args:      generateArgs(columnMap["type"]),
+			// the parts are rewritten with the new type
+			mutation: true,
 		},
 		{
 			condition: exists && columnDiffers(oldColumnMap, columnMap, "comment"),
@@ -119,7 +147,13 @@ func UpdateColumns(ctx context.Context, c *Client, table models.TableResource, c
 			query := fmt.Sprintf(change.query, change.args...)
 			tflog.Debug(ctx, fmt.Sprintf("Executing query: %s", query))
 
-			if err := executeQuery(ctx, c, query); err != nil {
+			var err error
+			if change.mutation {
+				err = executeMutation(ctx, c, table, query)
+			} else {
+				err = executeQuery(ctx, c, query)
+			}
+			if err != nil {
 				return fmt.Errorf("failed to modify column %s: %w", columnName, err)
 			}
 		}
@@ -127,6 +161,70 @@ func UpdateColumns(ctx context.Context, c *Client, table models.TableResource, c
 	return nil
 }
 
+// updateNestedColumn applies the changes of a Nested column on its sub-columns, as Clickhouse
+// stores them flattened and the whole Nested type can't be modified at once
+func updateNestedColumn(ctx context.Context, c *Client, table models.TableResource, clusterStatement string, columnName string, oldType string, newType string) error {
+	if models.ColumnTypesEqual(oldType, newType) {
+		return nil
+	}
+
+	oldFields, isOldNested := models.GetNestedFields(oldType)
+	newFields, _ := models.GetNestedFields(newType)
+	if !isOldNested {
+		return fmt.Errorf("failed to modify column %s: can't change a %s column into a Nested one", columnName, oldType)
+	}
+
+	oldFieldTypes := make(map[string]string)
+	for _, field := range oldFields {
+		oldFieldTypes[field.Name] = field.Type
+	}
+
+	var queries []string
+	location := ""
+	for _, field := range newFields {
+		oldFieldType, exists := oldFieldTypes[field.Name]
+		if !exists {
+			queries = append(queries, fmt.Sprintf(
+				"ALTER TABLE %s.%s %s ADD COLUMN `%s.%s` Array(%s) %s",
+				table.Database, table.Name, clusterStatement, columnName, field.Name, field.Type, location))
+		} else if !models.ColumnTypesEqual(oldFieldType, field.Type) {
+			queries = append(queries, fmt.Sprintf(
+				"ALTER TABLE %s.%s %s MODIFY COLUMN `%s.%s` Array(%s)",
+				table.Database, table.Name, clusterStatement, columnName, field.Name, field.Type))
+		}
+		delete(oldFieldTypes, field.Name)
+		location = fmt.Sprintf("AFTER `%s.%s`", columnName, field.Name)
+	}
+	for _, field := range oldFields {
+		if _, removed := oldFieldTypes[field.Name]; removed {
+			queries = append(queries, fmt.Sprintf(
+				"ALTER TABLE %s.%s %s DROP COLUMN `%s.%s`",
+				table.Database, table.Name, clusterStatement, columnName, field.Name))
+		}
+	}
+
+	for _, query := range queries {
+		tflog.Debug(ctx, fmt.Sprintf("Executing query: %s", query))
+		if err := executeMutation(ctx, c, table, query); err != nil {
+			return fmt.Errorf("failed to modify nested column %s: %w", columnName, err)
+		}
+	}
+	return nil
+}
+
+func buildAddColumnClause(columnMap map[string]interface{}) string {
+	return fmt.Sprintf(
+		"ADD COLUMN `%s` %s %s %s %s %s %s",
+		columnMap["name"],
+		columnMap["type"],
+		columnMap["default_kind"],
+		columnMap["default_expression"],
+		columnMap["compression_codec"],
+		getComment(columnMap["comment"].(string)),
+		columnMap["location"],
+	)
+}
+
 func columnDiffers(oldMap, newMap map[string]interface{}, keys ...string) bool {
 	for _, key := range keys {
 		if oldMap[key] != newMap[key] {
@@ -140,11 +238,11 @@ func dropOldColumns(ctx context.Context, c *Client, table models.TableResource,
 	for _, column := range oldColumns {
 		columnMap := column.(map[string]interface{})
 		if _, exists := newColumnsMap[columnMap["name"].(string)]; !exists {
-			err := executeQuery(ctx, c, fmt.Sprintf(
+			err := executeMutation(ctx, c, table, fmt.Sprintf(
 				"ALTER TABLE %s.%s %s DROP COLUMN %s",
 				table.Database, table.Name, clusterStatement, columnMap["name"]))
 			if err != nil {
-				return fmt.Errorf("dropping columns from Clickhouse table: %v", err)
+				return fmt.Errorf("dropping columns from Clickhouse table: %w", err)
 			}
 		}
 	}
@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
+	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const copyProgressLogInterval = 10 * time.Second
+
+// enginesWithoutData don't store the rows, reading them would insert the rows of the remote tables
+// again or consume the messages of the streaming engines
+var enginesWithoutData = []string{"Distributed", "Kafka", "RabbitMQ", "NATS"}
+
+// ReplaceTable recreates a table with a new definition without losing its data. The data is
+// copied into a shadow table, which is then atomically exchanged with the original table.
+func (c *Client) ReplaceTable(ctx context.Context, table models.TableResource, oldColumns []string, columnMapping map[string]string) error {
+	clusterStatement := common.GetClusterStatement(table.Cluster)
+
+	shadowTable := table
+	shadowTable.Name = fmt.Sprintf("%s__shadow_%d", table.Name, time.Now().Unix())
+
+	tflog.Info(ctx, fmt.Sprintf("Creating shadow table %s.%s", shadowTable.Database, shadowTable.Name))
+	if err := executeQuery(ctx, c, buildCreateTableOnClusterSentence(shadowTable)); err != nil {
+		return fmt.Errorf("creating shadow table: %w", err)
+	}
+
+	if common.Contains(enginesWithoutData, table.Engine) {
+		tflog.Info(ctx, fmt.Sprintf("Skipping data copy of %s table %s.%s", table.Engine, table.Database, table.Name))
+	} else if err := c.copyTableData(ctx, table, shadowTable, oldColumns, columnMapping); err != nil {
+		dropErr := executeQuery(ctx, c, fmt.Sprintf("DROP TABLE IF EXISTS %s.%s %s SYNC", shadowTable.Database, shadowTable.Name, clusterStatement))
+		if dropErr != nil {
+			return fmt.Errorf("copying data into shadow table: %v (dropping shadow table: %v)", err, dropErr)
+		}
+		return fmt.Errorf("copying data into shadow table: %v", err)
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Exchanging tables %s.%s and %s.%s", table.Database, table.Name, shadowTable.Database, shadowTable.Name))
+	query := fmt.Sprintf("EXCHANGE TABLES %s.%s AND %s.%s %s", table.Database, table.Name, shadowTable.Database, shadowTable.Name, clusterStatement)
+	if err := executeQueryWithoutRetry(ctx, c, query, nil); err != nil {
+		return fmt.Errorf("exchanging shadow table: %w", err)
+	}
+
+	// after the exchange, the shadow table holds the previous definition and data
+	tflog.Info(ctx, fmt.Sprintf("Dropping previous table definition %s.%s", shadowTable.Database, shadowTable.Name))
+	if err := executeQuery(ctx, c, fmt.Sprintf("DROP TABLE %s.%s %s SYNC", shadowTable.Database, shadowTable.Name, clusterStatement)); err != nil {
+		return fmt.Errorf("dropping previous table: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) copyTableData(ctx context.Context, source models.TableResource, target models.TableResource, sourceColumns []string, columnMapping map[string]string) error {
+	tflog.Info(ctx, fmt.Sprintf("Copying data from %s.%s into shadow table %s", source.Database, source.Name, target.Name))
+	var columns []string
+	var expressions []string
+	for _, column := range target.Columns {
+		// materialized and alias columns can't be inserted, they are computed by Clickhouse
+		if column.DefaultKind == "MATERIALIZED" || column.DefaultKind == "ALIAS" || column.DefaultKind == "EPHEMERAL" {
+			continue
+		}
+		if expression, ok := columnMapping[column.Name]; ok {
+			columns = append(columns, fmt.Sprintf("`%s`", column.Name))
+			expressions = append(expressions, expression)
+		} else if common.Contains(sourceColumns, column.Name) {
+			columns = append(columns, fmt.Sprintf("`%s`", column.Name))
+			expressions = append(expressions, fmt.Sprintf("`%s`", column.Name))
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s.%s has no column in common with the new definition", source.Database, source.Name)
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) SELECT %s FROM %s.%s",
+		target.Database, target.Name, strings.Join(columns, ", "),
+		strings.Join(expressions, ", "), source.Database, source.Name,
+	)
+
+	var mu sync.Mutex
+	var readRows, writtenRows uint64
+	lastLog := time.Now()
+	// the copy can take much longer than the default query execution time, it is bounded by the
+	// update timeout of the table instead
+	copySettings := clickhouse.Settings{"max_execution_time": 0}
+	copyProgress := clickhouse.WithProgress(func(p *clickhouse.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		readRows += p.Rows
+		writtenRows += p.WroteRows
+		if time.Since(lastLog) >= copyProgressLogInterval {
+			lastLog = time.Now()
+			tflog.Info(ctx, fmt.Sprintf("Copying %s.%s: %d rows read, %d rows written", source.Database, source.Name, readRows, writtenRows))
+		}
+	})
+
+	if err := executeQueryWithoutRetry(ctx, c, query, copySettings, copyProgress); err != nil {
+		return err
+	}
+	tflog.Info(ctx, fmt.Sprintf("Copied %s.%s: %d rows read, %d rows written", source.Database, source.Name, readRows, writtenRows))
+	return nil
+}
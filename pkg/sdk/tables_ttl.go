@@ -19,7 +19,8 @@ func UpdateTTL(ctx context.Context, c *Client, table models.TableResource, clust
 	if ttlExprsStatement != "" {
 		modifyTTLQuery := fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY TTL %s",
 			table.Database, table.Name, clusterStatement, ttlExprsStatement)
-		err := executeQuery(ctx, c, modifyTTLQuery)
+		// the TTL is materialized on the existing parts with a mutation
+		err := executeMutation(ctx, c, table, modifyTTLQuery)
 		if err != nil {
 			return err
 		}
@@ -11,11 +11,15 @@ import (
 func buildColumnsSentence(cols []models.ColumnDefinition) []string {
 	outColumn := make([]string, 0)
 	for _, col := range cols {
-		outColumn = append(outColumn, fmt.Sprintf("\t `%s` %s %s %s %s %s", col.Name, col.Type, col.DefaultKind, col.DefaultExpression, col.CompressionCodec, getComment(col.Comment)))
+		outColumn = append(outColumn, "\t "+buildColumnDefinition(col))
 	}
 	return outColumn
 }
 
+func buildColumnDefinition(col models.ColumnDefinition) string {
+	return fmt.Sprintf("`%s` %s %s %s %s %s", col.Name, col.Type, col.DefaultKind, col.DefaultExpression, col.CompressionCodec, getComment(col.Comment))
+}
+
 func buildIndexesSentence(indexes []models.IndexDefinition) []string {
 	outIndexes := make([]string, 0)
 	for _, index := range indexes {
@@ -58,6 +62,13 @@ func buildPrimaryKeySentence(primaryKey []string) string {
 	return ""
 }
 
+func buildSampleBySentence(sampleBy string) string {
+	if sampleBy != "" {
+		return fmt.Sprintf("SAMPLE BY %s", sampleBy)
+	}
+	return ""
+}
+
 func buildOrderBySentence(orderBy []string) string {
 	if len(orderBy) > 0 {
 		return fmt.Sprintf("ORDER BY (%v)", strings.Join(orderBy, ", "))
@@ -69,7 +80,7 @@ func buildSettingsSentence(settings map[string]string) string {
 	if len(settings) > 0 {
 		settingsList := make([]string, 0)
 		for key, value := range settings {
-			settingsList = append(settingsList, fmt.Sprintf("%s = '%s'", key, value))
+			settingsList = append(settingsList, buildSettingAssignment(key, value))
 		}
 		ret := fmt.Sprintf("SETTINGS %s", strings.Join(settingsList, ", "))
 		return ret
@@ -77,6 +88,11 @@ func buildSettingsSentence(settings map[string]string) string {
 	return ""
 }
 
+// the setting values may hold credentials, like the SASL password of Kafka tables
+func buildSettingAssignment(key string, value string) string {
+	return fmt.Sprintf("%s = '%s'", key, strings.ReplaceAll(strings.ReplaceAll(value, "\\", "\\\\"), "'", "\\'"))
+}
+
 func buildTTLSentence(ttl map[string]string) string {
 	if len(ttl) > 0 {
 		ttlList := make([]string, 0)
@@ -90,7 +106,10 @@ func buildTTLSentence(ttl map[string]string) string {
 }
 
 func buildCreateTableOnClusterSentence(resource models.TableResource) (query string) {
-	createStatement := common.GetCreateStatement("table")
+	return buildCreateTableSentence(resource, common.GetCreateStatement("table"))
+}
+
+func buildCreateTableSentence(resource models.TableResource, createStatement string) string {
 	clusterStatement := common.GetClusterStatement(resource.Cluster)
 
 	// Build columns and indexes
@@ -107,10 +126,12 @@ func buildCreateTableOnClusterSentence(resource models.TableResource) (query str
 	columnsStatement := ""
 	if len(columns) > 0 {
 		columnsStatement = fmt.Sprintf("(\n%s\n)", strings.Join(columns, ",\n"))
+	} else if resource.AsTable != "" {
+		columnsStatement = fmt.Sprintf("AS %s", resource.AsTable)
 	}
 
 	ret := fmt.Sprintf(
-		"%s %v.%v %v %v ENGINE = %v(%v) %s %s %s %s %s COMMENT '%s'",
+		"%s %v.%v %v %v ENGINE = %v(%v) %s %s %s %s %s %s COMMENT '%s'",
 		createStatement,
 		resource.Database,
 		resource.Name,
@@ -121,6 +142,7 @@ func buildCreateTableOnClusterSentence(resource models.TableResource) (query str
 		buildOrderBySentence(resource.OrderBy),
 		buildPrimaryKeySentence(resource.PrimaryKey),
 		buildPartitionBySentence(resource.PartitionBy),
+		buildSampleBySentence(resource.SampleBy),
 		buildTTLSentence(resource.TTL),
 		buildSettingsSentence(resource.Settings),
 		resource.Comment,
@@ -36,17 +36,18 @@ func (c *Client) CreateUser(ctx context.Context, userPlan models.UserResource) (
 		rolesList = append(rolesList, role.(string))
 	}
 	query := fmt.Sprintf(
-		"CREATE USER %s IDENTIFIED WITH sha256_password BY '%s'",
+		"CREATE USER %s %s IDENTIFIED WITH sha256_password BY '%s'",
 		userPlan.Name,
+		common.GetClusterStatement(userPlan.Cluster),
 		userPlan.Password,
 	)
 
 	if len(rolesList) > 0 {
 		query = fmt.Sprintf("%s DEFAULT ROLE %s", query, strings.Join(rolesList, ","))
 	}
-	err := c.Conn.Exec(ctx, query)
+	err := executeQuery(ctx, c, query)
 	if err != nil {
-		return nil, fmt.Errorf("error creating user: %s", err)
+		return nil, fmt.Errorf("error creating user: %w", err)
 	}
 	return c.GetUser(ctx, userPlan.Name)
 }
@@ -87,17 +88,18 @@ func (c *Client) UpdateUser(ctx context.Context, userPlan models.UserResource, r
 		}
 	}
 
+	clusterStatement := common.GetClusterStatement(userPlan.Cluster)
 	if len(grantRoles) > 0 {
-		err := c.Conn.Exec(ctx, fmt.Sprintf("GRANT %s TO %s", strings.Join(grantRoles, ","), stateUserName))
+		err := executeQuery(ctx, c, fmt.Sprintf("GRANT %s %s TO %s", clusterStatement, strings.Join(grantRoles, ","), stateUserName))
 		if err != nil {
-			return nil, fmt.Errorf("error granting roles to user: %s", err)
+			return nil, fmt.Errorf("error granting roles to user: %w", err)
 		}
 	}
 
 	if len(revokeRoles) > 0 {
-		err := c.Conn.Exec(ctx, fmt.Sprintf("REVOKE %s FROM %s", strings.Join(revokeRoles, ","), stateUserName))
+		err := executeQuery(ctx, c, fmt.Sprintf("REVOKE %s %s FROM %s", clusterStatement, strings.Join(revokeRoles, ","), stateUserName))
 		if err != nil {
-			return nil, fmt.Errorf("error revoking roles from user: %s", err)
+			return nil, fmt.Errorf("error revoking roles from user: %w", err)
 		}
 	}
 
@@ -114,20 +116,21 @@ func (c *Client) UpdateUser(ctx context.Context, userPlan models.UserResource, r
 
 	// After modify original role grants, we need to update default roles
 	query := fmt.Sprintf(
-		"ALTER USER %s%s%s DEFAULT ROLE %s",
+		"ALTER USER %s %s%s%s DEFAULT ROLE %s",
 		stateUserName,
+		clusterStatement,
 		changeNameClause,
 		changePasswordClause,
 		strings.Join(common.StringSetToList(userPlan.Roles), ","),
 	)
-	err = c.Conn.Exec(ctx, query)
+	err = executeQuery(ctx, c, query)
 	if err != nil {
-		return nil, fmt.Errorf("error updating user: %s", err)
+		return nil, fmt.Errorf("error updating user: %w", err)
 	}
 
 	return c.GetUser(ctx, userPlan.Name)
 }
 
-func (c *Client) DeleteUser(ctx context.Context, name string) error {
-	return c.Conn.Exec(ctx, fmt.Sprintf("DROP USER %s", name))
+func (c *Client) DeleteUser(ctx context.Context, name string, cluster string) error {
+	return executeQuery(ctx, c, fmt.Sprintf("DROP USER %s %s", name, common.GetClusterStatement(cluster)))
 }
@@ -7,10 +7,12 @@ import (
 
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func (c *Client) GetView(ctx context.Context, database string, view string) (*models.CHView, error) {
-	query := fmt.Sprintf("SELECT database, name, engine, as_select, comment FROM system.tables where database = '%s' and name = '%s'", database, view)
+	query := fmt.Sprintf("SELECT database, name, uuid, engine, as_select, comment, create_table_query FROM system.tables where database = '%s' and name = '%s'", database, view)
 	row := c.Conn.QueryRow(ctx, query)
 
 	if row.Err() != nil {
@@ -25,27 +27,118 @@ func (c *Client) GetView(ctx context.Context, database string, view string) (*mo
 	if err != nil {
 		return nil, fmt.Errorf("scanning Clickhouse view row: %v", err)
 	}
-	// the value read from system.tables is not formatted
-	normalizedQuery := common.NormalizeQuery(chView.Query)
-	chView.Query = normalizedQuery
-
 	return &chView, nil
 }
 
+// QueriesEqual tells whether two queries are equivalent once formatted by Clickhouse. When the
+// server can't format them, they are compared regardless of their case and whitespaces.
+func (c *Client) QueriesEqual(ctx context.Context, a string, b string) bool {
+	var formattedA, formattedB string
+	err := c.Conn.QueryRow(ctx, "SELECT formatQuery(?), formatQuery(?)", a, b).Scan(&formattedA, &formattedB)
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("formatting queries: %v", err))
+		return common.NormalizeQuery(a) == common.NormalizeQuery(b)
+	}
+	return formattedA == formattedB
+}
+
+// GetViewInnerTable returns the table where a materialized view without TO table stores its data
+func (c *Client) GetViewInnerTable(ctx context.Context, view models.CHView) (*models.CHTable, error) {
+	// Atomic databases name the inner table after the view UUID, Ordinary ones after the view name
+	for _, innerName := range []string{".inner_id." + view.UUID, ".inner." + view.Name} {
+		innerTable, err := c.GetTable(ctx, view.Database, innerName)
+		if err != nil {
+			return nil, fmt.Errorf("reading inner table of view %s.%s: %v", view.Database, view.Name, err)
+		}
+		if innerTable != nil {
+			return innerTable, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetViewRefresh returns the state of the refresh of a refreshable materialized view
+func (c *Client) GetViewRefresh(ctx context.Context, database string, view string) (*models.CHViewRefresh, error) {
+	query := fmt.Sprintf(
+		"SELECT toString(status) AS status, toString(next_refresh_time) AS next_refresh_time FROM system.view_refreshes WHERE database = '%s' AND view = '%s'",
+		database,
+		view,
+	)
+	row := c.Conn.QueryRow(ctx, query)
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading view refresh from Clickhouse: %v", row.Err())
+	}
+
+	var chViewRefresh models.CHViewRefresh
+	err := row.ScanStruct(&chViewRefresh)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse view refresh row: %v", err)
+	}
+	return &chViewRefresh, nil
+}
+
 func (c *Client) CreateView(ctx context.Context, resource models.ViewResource) error {
 	query := buildCreateOnClusterSentence(resource)
-	err := c.Conn.Exec(ctx, query)
+	err := executeQuery(ctx, c, query)
 	if err != nil {
-		return fmt.Errorf("creating Clickhouse view: %v", err)
+		return fmt.Errorf("creating Clickhouse view: %w", err)
+	}
+	return nil
+}
+
+// UpdateView applies the changes of a view in place: normal views are replaced with CREATE OR
+// REPLACE VIEW, while materialized views are altered so their target table is kept untouched
+func (c *Client) UpdateView(ctx context.Context, resource models.ViewResource, resourceData *schema.ResourceData) error {
+	if !resource.Materialized {
+		if resourceData.HasChanges("query", "comment", "column", "sql_security", "definer") {
+			err := executeQuery(ctx, c, buildCreateOrReplaceOnClusterSentence(resource))
+			if err != nil {
+				return fmt.Errorf("replacing Clickhouse view: %w", err)
+			}
+		}
+		return nil
+	}
+
+	clusterStatement := common.GetClusterStatement(resource.Cluster)
+	if resourceData.HasChange("refresh") && resource.Refresh != nil {
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY %s", resource.Database, resource.Name, clusterStatement, refreshStatement(resource.Refresh, false))
+		err := executeQuery(ctx, c, query)
+		if err != nil {
+			return fmt.Errorf("modifying Clickhouse materialized view refresh: %w", err)
+		}
+	}
+	if resourceData.HasChange("query") {
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY QUERY %s", resource.Database, resource.Name, clusterStatement, resource.Query)
+		err := executeQuery(ctx, c, query)
+		if err != nil {
+			return fmt.Errorf("modifying Clickhouse materialized view query: %w", err)
+		}
+	}
+	if sqlSecurity := sqlSecurityStatement(resource.SQLSecurity, resource.Definer); resourceData.HasChanges("sql_security", "definer") && sqlSecurity != "" {
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY %s", resource.Database, resource.Name, clusterStatement, sqlSecurity)
+		err := executeQuery(ctx, c, query)
+		if err != nil {
+			return fmt.Errorf("modifying Clickhouse materialized view sql security: %w", err)
+		}
+	}
+	if resourceData.HasChange("comment") {
+		query := fmt.Sprintf("ALTER TABLE %s.%s %s MODIFY COMMENT '%s'", resource.Database, resource.Name, clusterStatement, resource.Comment)
+		err := executeQuery(ctx, c, query)
+		if err != nil {
+			return fmt.Errorf("modifying Clickhouse materialized view comment: %w", err)
+		}
 	}
 	return nil
 }
 
 func (c *Client) DeleteView(ctx context.Context, resource models.ViewResource) error {
 	query := fmt.Sprintf("DROP VIEW if exists %s.%s %s", resource.Database, resource.Name, common.GetClusterStatement(resource.Cluster))
-	err := c.Conn.Exec(ctx, query)
+	err := executeQuery(ctx, c, query)
 	if err != nil {
-		return fmt.Errorf("deleting Clickhouse view: %v", err)
+		return fmt.Errorf("deleting Clickhouse view: %w", err)
 	}
 	return nil
 }
@@ -2,21 +2,36 @@ package sdk
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/common"
 	"github.com/FlowdeskMarkets/terraform-provider-clickhouse/pkg/models"
 )
 
 func buildCreateOnClusterSentence(resource models.ViewResource) (query string) {
+	return buildViewSentence("CREATE", resource)
+}
+
+func buildCreateOrReplaceOnClusterSentence(resource models.ViewResource) (query string) {
+	return buildViewSentence("CREATE OR REPLACE", resource)
+}
+
+func buildViewSentence(createStatement string, resource models.ViewResource) (query string) {
 	clusterStatement := common.GetClusterStatement(resource.Cluster)
 
 	ret := fmt.Sprintf(
-		"CREATE %s VIEW %v.%v %v %s as (%s) COMMENT '%s'",
+		"%s %s VIEW %v.%v %v %s %s %s %s %s %s as (%s) COMMENT '%s'",
+		createStatement,
 		isMaterializedStatement(resource.Materialized),
 		resource.Database,
 		resource.Name,
 		clusterStatement,
+		refreshStatement(resource.Refresh, true),
 		toTableStatement(resource.ToTable),
+		viewColumnsStatement(resource.Columns),
+		engineStatement(resource.Engine),
+		populateStatement(resource.Populate),
+		sqlSecurityStatement(resource.SQLSecurity, resource.Definer),
 		resource.Query,
 		resource.Comment,
 	)
@@ -36,3 +51,77 @@ func toTableStatement(toTable string) string {
 	}
 	return ""
 }
+
+func viewColumnsStatement(columns []models.ColumnDefinition) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	definitions := make([]string, 0, len(columns))
+	for _, column := range columns {
+		definitions = append(definitions, fmt.Sprintf("`%s` %s", column.Name, column.Type))
+	}
+	return "(" + strings.Join(definitions, ", ") + ")"
+}
+
+// sqlSecurityStatement returns the SQL SECURITY clause of a view. A definer implies the DEFINER
+// SQL security, it's left out for the other ones as it may be kept from the state.
+func sqlSecurityStatement(sqlSecurity string, definer string) string {
+	if definer != "" && (sqlSecurity == "" || sqlSecurity == "DEFINER") {
+		return "SQL SECURITY DEFINER DEFINER = " + definer
+	}
+	if sqlSecurity != "" {
+		return "SQL SECURITY " + sqlSecurity
+	}
+	return ""
+}
+
+func engineStatement(engine *models.ViewEngineResource) string {
+	if engine == nil {
+		return ""
+	}
+	return strings.Join([]string{
+		fmt.Sprintf("ENGINE = %s(%s)", engine.Engine, strings.Join(engine.EngineParams, ", ")),
+		buildOrderBySentence(engine.OrderBy),
+		buildPartitionBySentence(engine.PartitionBy),
+		buildPrimaryKeySentence(engine.PrimaryKey),
+		buildSettingsSentence(engine.Settings),
+	}, " ")
+}
+
+func populateStatement(populate bool) string {
+	if populate {
+		return "POPULATE"
+	}
+	return ""
+}
+
+// refreshStatement builds the REFRESH clause of refreshable materialized views. The APPEND
+// mode can only be set on creation, it's not part of ALTER TABLE ... MODIFY REFRESH
+func refreshStatement(refresh *models.ViewRefreshResource, withAppend bool) string {
+	if refresh == nil {
+		return ""
+	}
+
+	clauses := []string{"REFRESH"}
+	if refresh.Every != "" {
+		clauses = append(clauses, "EVERY "+refresh.Every)
+		if refresh.Offset != "" {
+			clauses = append(clauses, "OFFSET "+refresh.Offset)
+		}
+	} else {
+		clauses = append(clauses, "AFTER "+refresh.After)
+	}
+	if refresh.RandomizeFor != "" {
+		clauses = append(clauses, "RANDOMIZE FOR "+refresh.RandomizeFor)
+	}
+	if len(refresh.DependsOn) > 0 {
+		clauses = append(clauses, "DEPENDS ON "+strings.Join(refresh.DependsOn, ", "))
+	}
+	if len(refresh.Settings) > 0 {
+		clauses = append(clauses, buildSettingsSentence(refresh.Settings))
+	}
+	if withAppend && refresh.Append {
+		clauses = append(clauses, "APPEND")
+	}
+	return strings.Join(clauses, " ")
+}